@@ -8,20 +8,137 @@ import (
 type Config struct {
 	Teleport struct {
 		Addr                    string        `yaml:"addr"`
+		Addrs                   []string      `yaml:"addrs"`
 		Identity                string        `yaml:"identity"`
 		Reviewer                string        `yaml:"reviewer"`
 		IdentityRefreshInterval time.Duration `yaml:"identity_refresh_interval"`
+
+		// Connection options. Mode is one of "auto", "auth" or "proxy".
+		Mode                     string        `yaml:"mode"`
+		TLSRouting               bool          `yaml:"tls_routing"`
+		InsecureAddressDiscovery bool          `yaml:"insecure_address_discovery"`
+		DialTimeout              time.Duration `yaml:"dial_timeout"`
+		ClusterName              string        `yaml:"cluster_name"`
+		CAPins                   []string      `yaml:"ca_pins"`
 	} `yaml:"teleport"`
 
+	Log struct {
+		Level  string `yaml:"level"`
+		Format string `yaml:"format"`
+	} `yaml:"log"`
+
+	Tracing struct {
+		Enabled     bool     `yaml:"enabled"`
+		Endpoint    string   `yaml:"endpoint"`
+		Insecure    bool     `yaml:"insecure"`
+		SampleRatio *float64 `yaml:"sample_ratio"`
+		ServiceName string   `yaml:"service_name"`
+	} `yaml:"tracing"`
+
+	Redaction struct {
+		Patterns        []RedactionPattern `yaml:"patterns"`
+		MaxReasonLength int                `yaml:"max_reason_length"`
+		HashReasons     bool               `yaml:"hash_reasons"`
+	} `yaml:"redaction"`
+
+	Watch struct {
+		Mode             string        `yaml:"mode"`
+		PollInterval     time.Duration `yaml:"poll_interval"`
+		RetryInterval    time.Duration `yaml:"retry_interval"`
+		MaxFailures      int           `yaml:"max_failures"`
+		FallbackDuration time.Duration `yaml:"fallback_duration"`
+		ProbeInterval    time.Duration `yaml:"probe_interval"`
+		StaleThreshold   time.Duration `yaml:"stale_threshold"`
+	} `yaml:"watch"`
+
+	Processing struct {
+		Workers        int           `yaml:"workers"`
+		QueueSize      int           `yaml:"queue_size"`
+		RequestTimeout time.Duration `yaml:"request_timeout"`
+		Backpressure   string        `yaml:"backpressure"`
+		CacheTTL       time.Duration `yaml:"cache_ttl"`
+		ReviewRate     float64       `yaml:"review_rate"`
+		ReviewBurst    int           `yaml:"review_burst"`
+	} `yaml:"processing"`
+
+	Backlog struct {
+		PageSize int           `yaml:"page_size"`
+		MaxAge   time.Duration `yaml:"max_age"`
+	} `yaml:"backlog"`
+
+	Reload struct {
+		Interval       time.Duration `yaml:"interval"`
+		MaxDenials     int           `yaml:"max_denials"`
+		ConfirmVersion string        `yaml:"confirm_version"`
+	} `yaml:"reload"`
+
+	Reconciliation struct {
+		Enabled  bool          `yaml:"enabled"`
+		Interval time.Duration `yaml:"interval"`
+	} `yaml:"reconciliation"`
+
+	LeaderElection struct {
+		Enabled       bool          `yaml:"enabled"`
+		Backend       string        `yaml:"backend"`
+		LeaseName     string        `yaml:"lease_name"`
+		LeaseDuration time.Duration `yaml:"lease_duration"`
+		RetryInterval time.Duration `yaml:"retry_interval"`
+		LockFile      string        `yaml:"lock_file"`
+		Identity      string        `yaml:"identity"`
+	} `yaml:"leader_election"`
+
+	Sharding struct {
+		Enabled         bool          `yaml:"enabled"`
+		Backend         string        `yaml:"backend"`
+		GroupName       string        `yaml:"group_name"`
+		LeaseDuration   time.Duration `yaml:"lease_duration"`
+		RefreshInterval time.Duration `yaml:"refresh_interval"`
+		Directory       string        `yaml:"directory"`
+		Identity        string        `yaml:"identity"`
+		VirtualNodes    int           `yaml:"virtual_nodes"`
+	} `yaml:"sharding"`
+
 	Server struct {
-		HealthPort int    `yaml:"health_port"`
-		HealthPath string `yaml:"health_path"`
+		BindAddress             string        `yaml:"bind_address"`
+		HealthPort              int           `yaml:"health_port"`
+		HealthPath              string        `yaml:"health_path"`
+		MetricsPath             string        `yaml:"metrics_path"`
+		LivenessTimeout         time.Duration `yaml:"liveness_timeout"`
+		IdentityExpiryThreshold time.Duration `yaml:"identity_expiry_threshold"`
+		ReadyRequiresLeader     bool          `yaml:"ready_requires_leader"`
+		TLS                     TLS           `yaml:"tls"`
 	} `yaml:"server"`
 
+	Admin struct {
+		Enabled         bool   `yaml:"enabled"`
+		DecisionHistory int    `yaml:"decision_history"`
+		ListenAddress   string `yaml:"listen_address"`
+		TokenFile       string `yaml:"token_file"`
+		TLS             TLS    `yaml:"tls"`
+	} `yaml:"admin"`
+
+	Journal struct {
+		Enabled            bool          `yaml:"enabled"`
+		Path               string        `yaml:"path"`
+		MaxSizeMB          int           `yaml:"max_size_mb"`
+		MaxAge             time.Duration `yaml:"max_age"`
+		MaxBackups         int           `yaml:"max_backups"`
+		Sync               bool          `yaml:"sync"`
+		SigningKeyFile     string        `yaml:"signing_key_file"`
+		CheckpointInterval time.Duration `yaml:"checkpoint_interval"`
+	} `yaml:"journal"`
+
 	Rejection struct {
 		DefaultMessage string          `yaml:"default_message"`
 		Rules          []RejectionRule `yaml:"rules"`
 	} `yaml:"rejection"`
+
+	SLA struct {
+		Enabled        bool          `yaml:"enabled"`
+		Interval       time.Duration `yaml:"interval"`
+		DefaultMessage string        `yaml:"default_message"`
+		Rules          []SLARule     `yaml:"rules"`
+	} `yaml:"sla"`
 }
 
 // RejectionRule defines a single rejection rule with regex pattern and custom message.
@@ -31,3 +148,30 @@ type RejectionRule struct {
 	Message     string `yaml:"message"`
 	RolesRegex  string `yaml:"roles_regex,omitempty"`
 }
+
+// SLARule defines how long requests for matching roles may stay pending and
+// what happens once they are older than that.
+type SLARule struct {
+	Name       string        `yaml:"name"`
+	RolesRegex string        `yaml:"roles_regex,omitempty"`
+	MaxAge     time.Duration `yaml:"max_age"`
+	Action     string        `yaml:"action"`
+	Message    string        `yaml:"message,omitempty"`
+}
+
+// RedactionPattern replaces text matching a regular expression in logs and
+// exports. An empty replacement defaults to "[REDACTED:<name>]".
+type RedactionPattern struct {
+	Name        string `yaml:"name"`
+	Regex       string `yaml:"regex"`
+	Replacement string `yaml:"replacement,omitempty"`
+}
+
+// TLS configures TLS for an HTTP listener. Without a certificate the listener
+// serves plain HTTP. With a client CA, clients must present a certificate
+// signed by it.
+type TLS struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"`
+}
@@ -95,19 +95,114 @@ Container security context
 {{- toYaml .Values.securityContext }}
 {{- end }}
 
+{{/*
+Get the name of the secret holding the admin token, empty without a token
+*/}}
+{{- define "teleport-plugin-request-autoreviewer.admin.tokenSecretName" -}}
+{{- if .Values.admin.tokenSecret }}
+{{- .Values.admin.tokenSecret }}
+{{- else if .Values.admin.token }}
+{{- printf "%s-admin-token" (include "teleport-plugin-request-autoreviewer.fullname" .) }}
+{{- end }}
+{{- end }}
+
+{{/*
+Get the port of the admin listener, empty when it shares the health port
+*/}}
+{{- define "teleport-plugin-request-autoreviewer.admin.port" -}}
+{{- with .Values.admin.listenAddress }}
+{{- regexFind "[0-9]+$" . }}
+{{- end }}
+{{- end }}
+
+{{/*
+Validate admin configuration
+*/}}
+{{- define "teleport-plugin-request-autoreviewer.admin.validate" -}}
+{{- if .Values.admin.enabled }}
+  {{- if and (not .Values.admin.listenAddress) (not (include "teleport-plugin-request-autoreviewer.admin.tokenSecretName" .)) }}
+    {{- fail "admin.token or admin.tokenSecret is required when admin.enabled is true without admin.listenAddress" }}
+  {{- end }}
+  {{- if and .Values.admin.listenAddress (not (include "teleport-plugin-request-autoreviewer.admin.port" .)) }}
+    {{- fail "admin.listenAddress must end with a port, e.g. :8081" }}
+  {{- end }}
+  {{- if and .Values.admin.tls.secretName (not .Values.admin.listenAddress) }}
+    {{- fail "admin.tls requires admin.listenAddress" }}
+  {{- end }}
+  {{- if and (not (include "teleport-plugin-request-autoreviewer.admin.tokenSecretName" .)) (not (and .Values.admin.tls.secretName .Values.admin.tls.requireClientCert)) }}
+    {{- fail "admin.token, admin.tokenSecret or admin.tls.requireClientCert is required when admin.enabled is true" }}
+  {{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Validate journal configuration
+*/}}
+{{- define "teleport-plugin-request-autoreviewer.journal.validate" -}}
+{{- if and .Values.journal.enabled (not .Values.journal.signingKeySecret) (not .Values.journal.signingKeyFile) }}
+  {{- fail "journal.signingKeySecret or journal.signingKeyFile is required when journal.enabled is true" }}
+{{- end }}
+{{- end }}
+
 {{/*
 Generate config.yaml content
 */}}
 {{- define "teleport-plugin-request-autoreviewer.config" -}}
+{{- include "teleport-plugin-request-autoreviewer.admin.validate" . }}
+{{- include "teleport-plugin-request-autoreviewer.journal.validate" . }}
 teleport:
   addr: {{ .Values.teleport.addr | quote }}
   identity: "/etc/teleport/identity"
   reviewer: {{ .Values.teleport.reviewer | quote }}
   identity_refresh_interval: {{ .Values.teleport.identityRefreshInterval | quote }}
 
+log:
+  level: {{ .Values.log.level | quote }}
+  format: {{ .Values.log.format | quote }}
+
+leader_election:
+  enabled: {{ .Values.leaderElection.enabled }}
+  backend: "teleport"
+  lease_name: {{ .Values.leaderElection.leaseName | quote }}
+  lease_duration: {{ .Values.leaderElection.leaseDuration | quote }}
+  retry_interval: {{ .Values.leaderElection.retryInterval | quote }}
+
 server:
   health_port: {{ .Values.server.healthPort }}
   health_path: {{ .Values.server.healthPath | quote }}
+  metrics_path: {{ .Values.server.metricsPath | quote }}
+
+admin:
+  enabled: {{ .Values.admin.enabled }}
+  decision_history: {{ .Values.admin.decisionHistory }}
+  {{- with .Values.admin.listenAddress }}
+  listen_address: {{ . | quote }}
+  {{- end }}
+  {{- if include "teleport-plugin-request-autoreviewer.admin.tokenSecretName" . }}
+  token_file: "/etc/autoreviewer/admin/token"
+  {{- end }}
+  {{- if .Values.admin.tls.secretName }}
+  tls:
+    cert_file: "/etc/autoreviewer/admin-tls/tls.crt"
+    key_file: "/etc/autoreviewer/admin-tls/tls.key"
+    {{- if .Values.admin.tls.requireClientCert }}
+    client_ca_file: "/etc/autoreviewer/admin-tls/ca.crt"
+    {{- end }}
+  {{- end }}
+
+journal:
+  enabled: {{ .Values.journal.enabled }}
+  path: {{ .Values.journal.path | quote }}
+  max_size_mb: {{ .Values.journal.maxSizeMB }}
+  max_age: {{ .Values.journal.maxAge | quote }}
+  max_backups: {{ .Values.journal.maxBackups }}
+  sync: {{ .Values.journal.sync }}
+  {{- if .Values.journal.signingKeySecret }}
+  signing_key_file: "/etc/autoreviewer/journal/signing-key.pem"
+  {{- else }}
+  signing_key_file: {{ .Values.journal.signingKeyFile | quote }}
+  {{- end }}
+  checkpoint_interval: {{ .Values.journal.checkpointInterval | quote }}
 
 rejection:
   default_message: {{ .Values.rejection.defaultMessage | quote }}
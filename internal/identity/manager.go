@@ -2,13 +2,15 @@ package identity
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/gravitational/teleport/api/client"
 	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
 )
 
 // Manager handles automatic refresh of Teleport identity files
@@ -19,11 +21,11 @@ type Manager struct {
 	mu              sync.RWMutex
 	stopCh          chan struct{}
 	credUpdateCh    chan client.Credentials
-	logger          *log.Logger
+	logger          *slog.Logger
 }
 
 // NewManager creates a new identity manager
-func NewManager(identityPath string, refreshInterval time.Duration, logger *log.Logger) *Manager {
+func NewManager(identityPath string, refreshInterval time.Duration, logger *slog.Logger) *Manager {
 	return &Manager{
 		identityPath:    identityPath,
 		refreshInterval: refreshInterval,
@@ -55,7 +57,7 @@ func (m *Manager) Start(ctx context.Context) error {
 	ticker := time.NewTicker(m.refreshInterval)
 	defer ticker.Stop()
 
-	m.logger.Printf("Identity manager started, refresh interval: %v", m.refreshInterval)
+	m.logger.Info("Identity manager started", "refresh_interval", m.refreshInterval)
 
 	for {
 		select {
@@ -65,7 +67,7 @@ func (m *Manager) Start(ctx context.Context) error {
 			return nil
 		case <-ticker.C:
 			if err := m.refreshCredentials(); err != nil {
-				m.logger.Printf("Failed to refresh credentials: %v", err)
+				m.logger.Error("Failed to refresh credentials", logging.Err(err))
 			}
 		}
 	}
@@ -111,7 +113,7 @@ func (m *Manager) refreshCredentials() error {
 	// Notify about credential update
 	select {
 	case m.credUpdateCh <- newCreds:
-		m.logger.Printf("Credentials refreshed from %s (mod time: %v)", m.identityPath, stat.ModTime())
+		m.logger.Info("Credentials refreshed", "identity", m.identityPath, "mod_time", stat.ModTime())
 	default:
 		// Channel is full, skip this update
 	}
@@ -0,0 +1,157 @@
+package journal
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// Entry types
+const (
+	// TypeDecision entries hold a record passed to Append
+	TypeDecision = "decision"
+	// TypeCheckpoint entries hold a Checkpoint of the chain up to them
+	TypeCheckpoint = "checkpoint"
+)
+
+// Entry is a single line of the journal. Entries form a hash chain: each one
+// carries the hash of the one before it, across rotated files too, so
+// changing, removing or reordering an entry breaks the chain.
+type Entry struct {
+	Seq      uint64          `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Type     string          `json:"type"`
+	PrevHash string          `json:"prev_hash"`
+	Record   json.RawMessage `json:"record"`
+	Hash     string          `json:"hash"`
+}
+
+// computeHash returns the SHA-256 of the entry encoded without its hash
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Checkpoint vouches for the chain up to the entry before it. Since every
+// entry commits to the ones before it, a valid signature over the head
+// proves nothing up to it was altered without the signing key.
+type Checkpoint struct {
+	// Seq and Head are the sequence number and hash of the last entry covered
+	Seq  uint64 `json:"seq"`
+	Head string `json:"head"`
+	// Records is the number of decisions since the previous checkpoint
+	Records int `json:"records"`
+	// Time is when the checkpoint was written. Checkpoints are written
+	// periodically even without new decisions, so an old one shows that
+	// later ones were removed, or that the service stopped.
+	Time time.Time `json:"time"`
+	// KeyID identifies the key that signed the checkpoint
+	KeyID     string `json:"key_id,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// signedMessage is what a checkpoint signature covers
+func (c Checkpoint) signedMessage() []byte {
+	return fmt.Appendf(nil, "teleport-autoreviewer journal checkpoint\n%d\n%s\n%s\n", c.Seq, c.Head, c.Time.UTC().Format(time.RFC3339Nano))
+}
+
+// sign signs the checkpoint with key
+func (c *Checkpoint) sign(key ed25519.PrivateKey) {
+	c.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, c.signedMessage()))
+}
+
+// verify checks the checkpoint's signature against key
+func (c Checkpoint) verify(key ed25519.PublicKey) error {
+	if c.Signature == "" {
+		return trace.BadParameter("checkpoint is not signed")
+	}
+	if id := KeyID(key); c.KeyID != id {
+		return trace.BadParameter("checkpoint is signed by key %s, not %s", c.KeyID, id)
+	}
+	sig, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return trace.BadParameter("checkpoint signature is not valid base64")
+	}
+	if !ed25519.Verify(key, c.signedMessage(), sig) {
+		return trace.BadParameter("checkpoint signature does not match")
+	}
+	return nil
+}
+
+// KeyID returns a short fingerprint of a public key
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadSigningKey reads a PEM encoded PKCS #8 Ed25519 private key, as written
+// by `openssl genpkey -algorithm ed25519`
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse signing key %s: %v", path, err)
+	}
+	signer, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, trace.BadParameter("signing key %s is a %T, expected an Ed25519 key", path, key)
+	}
+	return signer, nil
+}
+
+// LoadPublicKey reads a PEM encoded Ed25519 public key, as written by
+// `openssl pkey -pubout`. A private key is accepted too, in which case its
+// public half is returned.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if block.Type == "PRIVATE KEY" {
+		key, err := LoadSigningKey(path)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return key.Public().(ed25519.PublicKey), nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse public key %s: %v", path, err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, trace.BadParameter("public key %s is a %T, expected an Ed25519 key", path, key)
+	}
+	return public, nil
+}
+
+// readPEM reads the first PEM block of a file
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to read key")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, trace.BadParameter("%s does not contain a PEM encoded key", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,60 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/gravitational/trace"
+)
+
+// HeadPath returns the file next to the journal at path that holds its last
+// checkpoint, e.g. decisions.jsonl.head for decisions.jsonl. Removing records
+// from the end of the journal along with their checkpoints leaves a valid
+// chain behind, but not one that reaches the head file.
+func HeadPath(path string) string {
+	return path + ".head"
+}
+
+// ReadHead reads the checkpoint in a head file. It returns a NotFound error
+// if there is none.
+func ReadHead(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, trace.NotFound("no journal head file at %s", path)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to read journal head file")
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, trace.BadParameter("journal head file %s is damaged: %v", path, err)
+	}
+	return &cp, nil
+}
+
+// writeHead replaces the head file at path with cp, so it holds either the
+// previous or the new checkpoint if writing it is interrupted
+func writeHead(path string, cp Checkpoint, sync bool) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return trace.Wrap(err, "failed to encode journal head")
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return trace.Wrap(err, "failed to write journal head file")
+	}
+	_, err = f.Write(append(data, '\n'))
+	if err == nil && sync {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return trace.Wrap(err, "failed to write journal head file")
+	}
+	return trace.Wrap(os.Rename(tmp, path), "failed to replace journal head file")
+}
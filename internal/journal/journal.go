@@ -0,0 +1,501 @@
+// Package journal appends records to a tamper-evident JSON lines file,
+// rotating it by size and age, as a durable record independent of
+// Teleport's audit log
+package journal
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/status"
+)
+
+// rotatedTimeFormat is the timestamp added to the name of rotated files.
+// It sorts in time order.
+const rotatedTimeFormat = "20060102T150405.000000000Z"
+
+// Options configures a journal
+type Options struct {
+	// Path is the file records are appended to
+	Path string
+	// MaxSize rotates the file before it grows past about this many bytes
+	MaxSize int64
+	// MaxAge rotates the file once it has been open this long
+	MaxAge time.Duration
+	// MaxBackups is how many rotated files are kept, all of them if zero
+	MaxBackups int
+	// Sync flushes every record to disk before Append returns
+	Sync bool
+	// SigningKey signs checkpoints. It is required, since a journal with
+	// unsigned checkpoints can be rewritten and rehashed without a trace.
+	SigningKey ed25519.PrivateKey
+}
+
+// Journal appends records as hash-chained JSON lines to a file. Checkpoints
+// written by Checkpoint, before rotating and on Close seal the chain so far,
+// and the last one is also kept in the head file next to it.
+type Journal struct {
+	opts     Options
+	registry *status.Registry
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	failing bool
+	closed  bool
+
+	// Sequence number and hash of the last entry, and the number of
+	// decisions written since the last checkpoint
+	seq       uint64
+	head      string
+	uncovered int
+}
+
+// Open opens the journal file for appending, creating it and its directory
+// if needed, and continues the hash chain from the last entry written. It
+// refuses to continue a chain that doesn't reach the checkpoint in the head
+// file. Write failures are reported into registry.
+func Open(opts Options, registry *status.Registry, logger *slog.Logger) (*Journal, error) {
+	if opts.SigningKey == nil {
+		return nil, trace.BadParameter("journal %s requires a signing key", opts.Path)
+	}
+	j := &Journal{opts: opts, registry: registry, logger: logger}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o700); err != nil {
+		return nil, trace.Wrap(err, "failed to create journal directory")
+	}
+	chained, err := j.resume()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := j.checkHead(chained); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := j.open(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !chained {
+		// Start the chain in a file of its own rather than after records
+		// written before the journal was hash-chained
+		logger.Warn("Decision journal is not hash-chained, rotating it to start a new chain", "path", opts.Path)
+		if err := j.rotate(); err != nil {
+			j.file.Close()
+			return nil, trace.Wrap(err)
+		}
+	}
+	registry.Set(status.ComponentJournal, status.KindInfo, true, "writing to "+opts.Path)
+	return j, nil
+}
+
+// resume picks up the chain from the last entry of the journal file, or of
+// the newest rotated file if it is empty. It returns false if the journal
+// file ends with an entry that is not hash-chained.
+func (j *Journal) resume() (bool, error) {
+	last, uncovered, err := lastEntry(j.opts.Path)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	if last != nil && last.Hash == "" {
+		return false, nil
+	}
+	if last == nil {
+		rotated, err := Rotated(j.opts.Path)
+		if err != nil {
+			return false, trace.Wrap(err)
+		}
+		if len(rotated) == 0 {
+			return true, nil
+		}
+		last, uncovered, err = lastEntry(rotated[len(rotated)-1])
+		if err != nil {
+			return false, trace.Wrap(err)
+		}
+		// Rotated files are never written again, so one that isn't chained
+		// is simply followed by a new chain
+		if last == nil || last.Hash == "" {
+			return true, nil
+		}
+	}
+	j.seq, j.head, j.uncovered = last.Seq, last.Hash, uncovered
+	return true, nil
+}
+
+// checkHead checks that the chain picked up by resume reaches the checkpoint
+// in the head file and matches it there, so records removed from the end of
+// the journal are not papered over by new ones
+func (j *Journal) checkHead(chained bool) error {
+	headPath := HeadPath(j.opts.Path)
+	head, err := ReadHead(headPath)
+	if trace.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := head.verify(j.opts.SigningKey.Public().(ed25519.PublicKey)); err != nil {
+		return trace.BadParameter("journal head file %s: %v", headPath, err)
+	}
+	if !chained || j.seq < head.Seq {
+		return trace.BadParameter("journal %s ends before record %d, which %s says was checkpointed, records were removed; "+
+			"check it with verify-journal and move the journal and %s aside", j.opts.Path, head.Seq, headPath, headPath)
+	}
+
+	files, err := Rotated(j.opts.Path)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e, err := findEntry(append(files, j.opts.Path), head.Seq)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if e == nil || e.Hash != head.Head {
+		return trace.BadParameter("record %d of journal %s does not match the checkpoint in %s, the journal was altered; "+
+			"check it with verify-journal and move the journal and %s aside", head.Seq, j.opts.Path, headPath, headPath)
+	}
+	return nil
+}
+
+// findEntry returns the entry with sequence number seq from files, oldest
+// first, searching the newest file first, or nil if there is none
+func findEntry(files []string, seq uint64) (*Entry, error) {
+	for i := len(files) - 1; i >= 0; i-- {
+		f, err := os.Open(files[i])
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to open journal")
+		}
+		var found *Entry
+		r := bufio.NewReader(f)
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				break
+			}
+			var e Entry
+			if json.Unmarshal(line, &e) == nil && e.Hash != "" && e.Seq == seq {
+				found = &e
+				break
+			}
+		}
+		f.Close()
+		if found != nil {
+			return found, nil
+		}
+	}
+	return nil, nil
+}
+
+// lastEntry returns the last entry of a file, nil if it is missing or empty,
+// and the number of decisions after the last checkpoint
+func lastEntry(path string) (*Entry, int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, trace.Wrap(err, "failed to open journal")
+	}
+	defer f.Close()
+
+	var last *Entry
+	uncovered := 0
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return nil, 0, trace.BadParameter("journal %s ends with an incomplete record, check it with verify-journal and move it aside", path)
+			}
+			return last, uncovered, nil
+		}
+		if err != nil {
+			return nil, 0, trace.Wrap(err, "failed to read journal")
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, 0, trace.BadParameter("journal %s holds a damaged record, check it with verify-journal and move it aside", path)
+		}
+		if e.Type == TypeCheckpoint {
+			uncovered = 0
+		} else {
+			uncovered++
+		}
+		last = &e
+	}
+}
+
+// open opens the journal file. Must be called with the lock held.
+func (j *Journal) open() error {
+	f, err := os.OpenFile(j.opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return trace.Wrap(err, "failed to open journal")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return trace.Wrap(err, "failed to stat journal")
+	}
+	j.file = f
+	j.size = info.Size()
+	j.opened = time.Now()
+	return nil
+}
+
+// Append writes record as the next decision entry of the chain, sealing and
+// rotating the file first if the entry would take it past the size limit or
+// it is older than the age limit
+func (j *Journal) Append(record any) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return j.fail(trace.Wrap(err, "failed to encode journal record"))
+	}
+	if err := j.ready(); err != nil {
+		return j.fail(err)
+	}
+	if j.needsRotation(int64(len(data))) {
+		if err := j.checkpoint(); err != nil {
+			return j.fail(err)
+		}
+		if err := j.rotate(); err != nil {
+			return j.fail(err)
+		}
+	}
+	if err := j.write(TypeDecision, data); err != nil {
+		return j.fail(err)
+	}
+	j.uncovered++
+	metrics.JournalRecords.Inc()
+	j.recovered()
+	return nil
+}
+
+// Checkpoint seals the chain so far with a signed checkpoint. It writes one
+// even if no decision was written since the last one, so the time of the
+// last checkpoint shows whether later ones were removed.
+func (j *Journal) Checkpoint() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.seq == 0 {
+		return nil
+	}
+	if err := j.ready(); err != nil {
+		return j.fail(err)
+	}
+	if err := j.writeCheckpoint(); err != nil {
+		return j.fail(err)
+	}
+	j.recovered()
+	return nil
+}
+
+// ready checks the journal can be written to, reopening the file if a
+// failed rotation left it closed. Must be called with the lock held.
+func (j *Journal) ready() error {
+	if j.closed {
+		return trace.ConnectionProblem(nil, "journal is closed")
+	}
+	if j.file == nil {
+		return trace.Wrap(j.open())
+	}
+	return nil
+}
+
+// checkpoint writes a checkpoint of the chain up to the last entry, if any
+// decision was written since the last one. Must be called with the lock held.
+func (j *Journal) checkpoint() error {
+	if j.uncovered == 0 {
+		return nil
+	}
+	return j.writeCheckpoint()
+}
+
+// writeCheckpoint writes a checkpoint of the chain up to the last entry and
+// records it in the head file. Must be called with the lock held.
+func (j *Journal) writeCheckpoint() error {
+	cp := Checkpoint{Seq: j.seq, Head: j.head, Records: j.uncovered, Time: time.Now().UTC()}
+	cp.sign(j.opts.SigningKey)
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return trace.Wrap(err, "failed to encode checkpoint")
+	}
+	if err := j.write(TypeCheckpoint, data); err != nil {
+		return trace.Wrap(err)
+	}
+	j.uncovered = 0
+	metrics.JournalCheckpoints.Inc()
+	metrics.JournalCheckpointSeq.Set(float64(cp.Seq))
+	metrics.JournalCheckpointTimestamp.Set(float64(cp.Time.Unix()))
+	// Logged too so the chain head can be checked against a copy kept
+	// outside the journal's directory
+	j.logger.Info("Checkpointed decision journal", "seq", cp.Seq, "head", cp.Head, "records", cp.Records)
+	return trace.Wrap(writeHead(HeadPath(j.opts.Path), cp, j.opts.Sync))
+}
+
+// write appends the next entry of the chain. Must be called with the lock
+// held.
+func (j *Journal) write(typ string, record json.RawMessage) error {
+	e := Entry{
+		Seq:      j.seq + 1,
+		Time:     time.Now().UTC(),
+		Type:     typ,
+		PrevHash: j.head,
+		Record:   record,
+	}
+	hash, err := e.computeHash()
+	if err != nil {
+		return trace.Wrap(err, "failed to hash journal record")
+	}
+	e.Hash = hash
+	line, err := json.Marshal(e)
+	if err != nil {
+		return trace.Wrap(err, "failed to encode journal record")
+	}
+	line = append(line, '\n')
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return trace.Wrap(err, "failed to write journal record")
+	}
+	j.seq, j.head = e.Seq, e.Hash
+	if j.opts.Sync {
+		if err := j.file.Sync(); err != nil {
+			return trace.Wrap(err, "failed to sync journal")
+		}
+	}
+	return nil
+}
+
+// needsRotation reports whether the file must be rotated before writing n
+// more bytes. An empty file is never rotated. Must be called with the lock
+// held.
+func (j *Journal) needsRotation(n int64) bool {
+	if j.size == 0 {
+		return false
+	}
+	return (j.opts.MaxSize > 0 && j.size+n > j.opts.MaxSize) ||
+		(j.opts.MaxAge > 0 && time.Since(j.opened) > j.opts.MaxAge)
+}
+
+// rotate renames the current file with a timestamp, opens a new one and
+// removes the oldest rotated files beyond the backup limit. Must be called
+// with the lock held.
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		j.logger.Warn("Failed to close journal before rotation", logging.Err(err))
+	}
+	j.file = nil
+
+	rotated := j.rotatedPath(time.Now())
+	if err := os.Rename(j.opts.Path, rotated); err != nil {
+		// Keep appending to the current file rather than losing records
+		if openErr := j.open(); openErr != nil {
+			return trace.NewAggregate(trace.Wrap(err, "failed to rotate journal"), openErr)
+		}
+		return trace.Wrap(err, "failed to rotate journal")
+	}
+	if err := j.open(); err != nil {
+		return trace.Wrap(err)
+	}
+	j.logger.Info("Rotated decision journal", "path", j.opts.Path, "rotated", rotated)
+	metrics.JournalRotations.Inc()
+
+	j.prune()
+	return nil
+}
+
+// rotatedPath returns the name a file rotated at t gets, e.g.
+// decisions-20240115T103045.000000000Z.jsonl for decisions.jsonl
+func (j *Journal) rotatedPath(t time.Time) string {
+	ext := filepath.Ext(j.opts.Path)
+	base := strings.TrimSuffix(j.opts.Path, ext)
+	return fmt.Sprintf("%s-%s%s", base, t.UTC().Format(rotatedTimeFormat), ext)
+}
+
+// Rotated returns the rotated files of the journal at path, oldest first
+func Rotated(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// prune removes the oldest rotated files beyond the backup limit. Must be
+// called with the lock held.
+func (j *Journal) prune() {
+	if j.opts.MaxBackups <= 0 {
+		return
+	}
+	rotated, err := Rotated(j.opts.Path)
+	if err != nil {
+		j.logger.Warn("Failed to list rotated journals", logging.Err(err))
+		return
+	}
+	for len(rotated) > j.opts.MaxBackups {
+		if err := os.Remove(rotated[0]); err != nil {
+			j.logger.Warn("Failed to remove old journal", "path", rotated[0], logging.Err(err))
+		}
+		rotated = rotated[1:]
+	}
+}
+
+// fail reports a write failure and returns err. Must be called with the lock
+// held.
+func (j *Journal) fail(err error) error {
+	j.failing = true
+	metrics.JournalErrors.Inc()
+	j.registry.Error(status.ComponentJournal, err)
+	j.registry.Set(status.ComponentJournal, status.KindInfo, false, "failed to write the last record")
+	return err
+}
+
+// recovered clears a reported write failure after a successful write. Must
+// be called with the lock held.
+func (j *Journal) recovered() {
+	if j.failing {
+		j.failing = false
+		j.registry.Set(status.ComponentJournal, status.KindInfo, true, "writing to "+j.opts.Path)
+	}
+}
+
+// Close seals the chain with a final checkpoint, then flushes and closes the
+// journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.closed = true
+	if j.file == nil {
+		return nil
+	}
+	var errs []error
+	if err := j.checkpoint(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, j.file.Sync(), j.file.Close())
+	j.file = nil
+	return trace.NewAggregate(errs...)
+}
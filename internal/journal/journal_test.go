@@ -0,0 +1,243 @@
+package journal
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"teleport-autoreviewer/internal/status"
+)
+
+type testRecord struct {
+	RequestID string `json:"request_id"`
+	Outcome   string `json:"outcome"`
+}
+
+func newTestKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func openTestJournal(t *testing.T, opts Options) *Journal {
+	t.Helper()
+	j, err := Open(opts, status.NewRegistry(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return j
+}
+
+// writeTestJournal writes decisions records, checkpointing after every
+// checkpointEvery of them, and closes the journal
+func writeTestJournal(t *testing.T, opts Options, decisions, checkpointEvery int) {
+	t.Helper()
+	j := openTestJournal(t, opts)
+	for i := range decisions {
+		if err := j.Append(testRecord{RequestID: strings.Repeat("r", i+1), Outcome: "denied"}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if checkpointEvery > 0 && (i+1)%checkpointEvery == 0 {
+			if err := j.Checkpoint(); err != nil {
+				t.Fatalf("Checkpoint: %v", err)
+			}
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// readLines returns the lines of a file, each with its newline
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.SplitAfter(data, []byte("\n"))
+}
+
+func writeLines(t *testing.T, path string, lines [][]byte) {
+	t.Helper()
+	if err := os.WriteFile(path, bytes.Join(lines, nil), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func verifyTestJournal(t *testing.T, path string, opts VerifyOptions) *Report {
+	t.Helper()
+	report, err := VerifyJournal(path, opts)
+	if err != nil {
+		t.Fatalf("VerifyJournal: %v", err)
+	}
+	return report
+}
+
+func requireProblem(t *testing.T, report *Report, substr string) {
+	t.Helper()
+	for _, p := range report.Problems {
+		if strings.Contains(p.Message, substr) {
+			return
+		}
+	}
+	t.Fatalf("expected a problem containing %q, got %v", substr, report.Problems)
+}
+
+func TestVerifyIntact(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	writeTestJournal(t, Options{Path: path, MaxSize: 1024, SigningKey: key}, 20, 3)
+
+	files, err := Rotated(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected the journal to be rotated, got %d rotated files", len(files))
+	}
+
+	report := verifyTestJournal(t, path, VerifyOptions{Key: key.Public().(ed25519.PublicKey), MaxCheckpointAge: time.Minute})
+	if !report.OK() || len(report.Warnings) > 0 {
+		t.Fatalf("expected an intact journal, got problems %v and warnings %v", report.Problems, report.Warnings)
+	}
+	if report.Records != 20 || report.Signed != report.Checkpoints {
+		t.Fatalf("expected 20 records with signed checkpoints, got %d records and %d of %d checkpoints signed",
+			report.Records, report.Signed, report.Checkpoints)
+	}
+}
+
+func TestVerifyDetectsModifiedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	writeTestJournal(t, Options{Path: path, SigningKey: newTestKey(t)}, 5, 0)
+
+	lines := readLines(t, path)
+	lines[2] = bytes.Replace(lines[2], []byte(`"denied"`), []byte(`"approved"`), 1)
+	writeLines(t, path, lines)
+
+	requireProblem(t, verifyTestJournal(t, path, VerifyOptions{}), "record 3 was modified")
+}
+
+func TestVerifyDetectsRemovedRotatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	writeTestJournal(t, Options{Path: path, MaxSize: 512, SigningKey: newTestKey(t)}, 20, 0)
+
+	files, err := Rotated(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 3 {
+		t.Fatalf("expected at least 3 rotated files, got %d", len(files))
+	}
+	if err := os.Remove(files[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	requireProblem(t, verifyTestJournal(t, path, VerifyOptions{}), "records were removed or reordered")
+}
+
+func TestVerifyDetectsTruncation(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	writeTestJournal(t, Options{Path: path, SigningKey: key}, 6, 3)
+
+	// Remove the last 3 decisions along with the checkpoints after them,
+	// leaving a chain that is valid on its own
+	lines := readLines(t, path)
+	writeLines(t, path, lines[:4])
+
+	public := key.Public().(ed25519.PublicKey)
+	report := verifyTestJournal(t, path, VerifyOptions{Key: public})
+	requireProblem(t, report, "records were removed from its end")
+
+	// Without the head file, the expected record and the checkpoint age
+	// still give it away
+	if err := os.Remove(HeadPath(path)); err != nil {
+		t.Fatal(err)
+	}
+	report = verifyTestJournal(t, path, VerifyOptions{Key: public, ExpectSeq: 8})
+	requireProblem(t, report, "records were removed from its end")
+
+	report = verifyTestJournal(t, path, VerifyOptions{Key: public, MaxCheckpointAge: time.Nanosecond})
+	if !report.OK() {
+		t.Fatalf("expected no problems, got %v", report.Problems)
+	}
+	if !strings.Contains(strings.Join(report.Warnings, "\n"), "the last checkpoint was written") {
+		t.Fatalf("expected a warning about the last checkpoint, got %v", report.Warnings)
+	}
+}
+
+func TestVerifyDetectsForgedHead(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	writeTestJournal(t, Options{Path: path, SigningKey: key}, 3, 0)
+
+	head, err := ReadHead(HeadPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head.sign(newTestKey(t))
+	if err := writeHead(HeadPath(path), *head, false); err != nil {
+		t.Fatal(err)
+	}
+
+	report := verifyTestJournal(t, path, VerifyOptions{Key: key.Public().(ed25519.PublicKey)})
+	requireProblem(t, report, "checkpoint is signed by key")
+}
+
+func TestOpenRefusesTruncatedJournal(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	opts := Options{Path: path, SigningKey: key}
+	writeTestJournal(t, opts, 6, 3)
+
+	lines := readLines(t, path)
+	writeLines(t, path, lines[:4])
+
+	_, err := Open(opts, status.NewRegistry(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err == nil || !strings.Contains(err.Error(), "records were removed") {
+		t.Fatalf("expected Open to refuse a truncated journal, got %v", err)
+	}
+}
+
+func TestOpenResumesChain(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	opts := Options{Path: path, SigningKey: key}
+	writeTestJournal(t, opts, 3, 0)
+	writeTestJournal(t, opts, 3, 0)
+
+	report := verifyTestJournal(t, path, VerifyOptions{Key: key.Public().(ed25519.PublicKey)})
+	if !report.OK() || report.Records != 6 || report.LastSeq != 8 {
+		t.Fatalf("expected 6 records up to record 8, got %d up to %d with problems %v",
+			report.Records, report.LastSeq, report.Problems)
+	}
+}
+
+func TestVerifyWithoutKeyIsUnverified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	writeTestJournal(t, Options{Path: path, SigningKey: newTestKey(t)}, 3, 0)
+
+	report := verifyTestJournal(t, path, VerifyOptions{})
+	if !report.OK() || report.Verified() {
+		t.Fatalf("expected no problems but an unverified journal, got problems %v and %q", report.Problems, report.Unverified)
+	}
+}
+
+func TestOpenRequiresSigningKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	_, err := Open(Options{Path: path}, status.NewRegistry(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err == nil {
+		t.Fatal("expected Open to refuse a journal without a signing key")
+	}
+}
@@ -0,0 +1,326 @@
+package journal
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// Problem is a sign that the journal was altered
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// VerifyOptions configures what a journal is verified against
+type VerifyOptions struct {
+	// Key checks checkpoint signatures, they are not checked if it is nil
+	Key ed25519.PublicKey
+	// HeadFile holds the last checkpoint the service wrote, which the chain
+	// must reach and match. VerifyJournal defaults it to the journal's head
+	// file if there is one.
+	HeadFile string
+	// ExpectSeq and ExpectHead are a record the chain must reach and its
+	// hash, e.g. from a checkpoint the service logged. ExpectHead is not
+	// compared if empty.
+	ExpectSeq  uint64
+	ExpectHead string
+	// MaxCheckpointAge warns when the last checkpoint is older than this,
+	// since records removed along with the checkpoints after them leave no
+	// other trace in the journal itself
+	MaxCheckpointAge time.Duration
+}
+
+// Report is the result of verifying a journal
+type Report struct {
+	Files       []string
+	Records     int
+	Checkpoints int
+	// Signed is the number of checkpoints with a valid signature
+	Signed   int
+	FirstSeq uint64
+	LastSeq  uint64
+	// LastCheckpoint is the last entry covered by a checkpoint, and signed
+	// by the key if one was given, and LastCheckpointTime when it was written
+	LastCheckpoint     uint64
+	LastCheckpointTime time.Time
+	// Problems prove the journal was altered, Warnings are limits on what
+	// could be proven
+	Problems []Problem
+	Warnings []string
+	// Unverified explains why the journal could not be proven intact, even
+	// without problems, e.g. because no key was given
+	Unverified string
+}
+
+// OK reports whether no problems were found
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verified reports whether the journal was proven intact up to its last
+// signed checkpoint
+func (r *Report) Verified() bool {
+	return r.OK() && r.Unverified == ""
+}
+
+// verifier walks the entries of a journal's files in order
+type verifier struct {
+	opts   VerifyOptions
+	report *Report
+	// wanted are the records the chain must reach, hashes the hashes of
+	// those found
+	wanted map[uint64]bool
+	hashes map[uint64]string
+	// prev is the last entry read, nil at the start of the chain or after a
+	// damaged line
+	prev       *Entry
+	started    bool
+	damaged    bool
+	sinceCheck int
+}
+
+// VerifyJournal verifies the journal at path along with its rotated files
+// and its head file
+func VerifyJournal(path string, opts VerifyOptions) (*Report, error) {
+	files, err := Rotated(path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, trace.Wrap(err)
+	}
+	if len(files) == 0 {
+		return nil, trace.NotFound("no journal found at %s", path)
+	}
+
+	var warnings []string
+	if opts.HeadFile == "" {
+		opts.HeadFile = HeadPath(path)
+		if _, err := os.Stat(opts.HeadFile); errors.Is(err, os.ErrNotExist) {
+			opts.HeadFile = ""
+			warnings = append(warnings, fmt.Sprintf(
+				"no head file at %s, records removed from the end of the journal along with their checkpoints could only show as an old last checkpoint",
+				HeadPath(path)))
+		}
+	}
+	report, err := Verify(files, opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	report.Warnings = append(warnings, report.Warnings...)
+	return report, nil
+}
+
+// Verify checks that files, oldest first, form an unbroken hash chain whose
+// checkpoints match it, and that it reaches the records opts expects
+func Verify(files []string, opts VerifyOptions) (*Report, error) {
+	var head *Checkpoint
+	if opts.HeadFile != "" {
+		var err error
+		if head, err = ReadHead(opts.HeadFile); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	v := &verifier{opts: opts, report: &Report{Files: files}, wanted: map[uint64]bool{}, hashes: map[uint64]string{}}
+	if head != nil {
+		v.wanted[head.Seq] = true
+	}
+	if opts.ExpectSeq > 0 {
+		v.wanted[opts.ExpectSeq] = true
+	}
+	for i, file := range files {
+		if err := v.verifyFile(file, i == len(files)-1); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	r := v.report
+	if head != nil {
+		if opts.Key != nil {
+			if err := head.verify(opts.Key); err != nil {
+				r.Problems = append(r.Problems, Problem{File: opts.HeadFile, Message: err.Error()})
+			}
+		}
+		v.expect(opts.HeadFile, head.Seq, head.Head)
+	}
+	if opts.ExpectSeq > 0 {
+		v.expect("expected record", opts.ExpectSeq, opts.ExpectHead)
+	}
+	switch {
+	case !v.started:
+		r.Warnings = append(r.Warnings, "the journal holds no hash-chained records")
+	case v.sinceCheck > 0:
+		r.Warnings = append(r.Warnings, fmt.Sprintf(
+			"the last %d records are not covered by a checkpoint, so their truncation could not be detected; "+
+				"the service is running, did not shut down cleanly, or they were truncated", v.sinceCheck))
+	}
+	if age := time.Since(r.LastCheckpointTime); opts.MaxCheckpointAge > 0 && r.Checkpoints > 0 && age > opts.MaxCheckpointAge {
+		r.Warnings = append(r.Warnings, fmt.Sprintf(
+			"the last checkpoint was written %s ago, longer than the checkpoint interval; "+
+				"the service is not running, or records were removed along with the checkpoints after them",
+			age.Truncate(time.Second)))
+	}
+	switch {
+	case opts.Key == nil:
+		r.Unverified = "no public key given, so a journal rewritten and rehashed from its first record would look intact"
+	case r.Signed == 0:
+		r.Unverified = "no signed checkpoint covers the journal"
+	}
+	return r, nil
+}
+
+// expect checks that the chain reaches record seq and, unless head is empty,
+// that its hash is head. source names where the expectation comes from.
+func (v *verifier) expect(source string, seq uint64, head string) {
+	r := v.report
+	hash, ok := v.hashes[seq]
+	switch {
+	case !ok && r.LastSeq < seq:
+		r.Problems = append(r.Problems, Problem{File: source, Message: fmt.Sprintf(
+			"the journal ends at record %d, before record %d, records were removed from its end", r.LastSeq, seq)})
+	case !ok:
+		r.Problems = append(r.Problems, Problem{File: source, Message: fmt.Sprintf("record %d is missing from the journal", seq)})
+	case head != "" && hash != head:
+		r.Problems = append(r.Problems, Problem{File: source, Message: fmt.Sprintf(
+			"record %d has hash %s, not %s, the journal was rewritten", seq, hash, head)})
+	}
+}
+
+// verifyFile checks the entries of a single file
+func (v *verifier) verifyFile(file string, last bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return trace.Wrap(err, "failed to open journal")
+	}
+	defer f.Close()
+
+	problem := func(line int, format string, args ...any) {
+		v.report.Problems = append(v.report.Problems, Problem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var lastType string
+	n := 0
+	r := bufio.NewReader(f)
+	for {
+		n++
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				problem(n, "incomplete record")
+			}
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err, "failed to read journal")
+		}
+
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			problem(n, "damaged record: %v", err)
+			v.prev, v.damaged = nil, true
+			lastType = ""
+			continue
+		}
+		if e.Hash == "" {
+			if n == 1 {
+				v.report.Warnings = append(v.report.Warnings, file+" was written before the journal was hash-chained and was skipped")
+				return nil
+			}
+			problem(n, "record is not part of the hash chain")
+			v.prev, v.damaged = nil, true
+			lastType = ""
+			continue
+		}
+		v.verifyEntry(&e, func(format string, args ...any) { problem(n, format, args...) })
+		lastType = e.Type
+	}
+
+	// Every file is sealed with a checkpoint before it is rotated
+	if !last && lastType != TypeCheckpoint && v.started {
+		problem(n-1, "rotated file does not end with a checkpoint, records were removed from its end")
+	}
+	return nil
+}
+
+// verifyEntry checks an entry against the one before it and, for
+// checkpoints, the chain head and signature
+func (v *verifier) verifyEntry(e *Entry, problem func(format string, args ...any)) {
+	r := v.report
+	if hash, err := e.computeHash(); err != nil || hash != e.Hash {
+		problem("record %d was modified, its hash does not match its content", e.Seq)
+	}
+
+	switch {
+	case v.prev != nil:
+		if e.Seq != v.prev.Seq+1 {
+			problem("expected record %d but found %d, records were removed or reordered", v.prev.Seq+1, e.Seq)
+		}
+		if e.PrevHash != v.prev.Hash {
+			problem("record %d does not follow record %d in the hash chain", e.Seq, v.prev.Seq)
+		}
+	case !v.started:
+		r.FirstSeq = e.Seq
+		if e.PrevHash != "" {
+			r.Warnings = append(r.Warnings, fmt.Sprintf(
+				"the chain starts at record %d, earlier records were rotated away or removed", e.Seq))
+		}
+	case !v.damaged:
+		// A new chain after a file that was not hash-chained
+		if e.PrevHash != "" {
+			problem("record %d follows a record that is missing", e.Seq)
+		}
+	}
+	v.started, v.damaged = true, false
+	r.LastSeq = e.Seq
+	if v.wanted[e.Seq] {
+		v.hashes[e.Seq] = e.Hash
+	}
+
+	switch e.Type {
+	case TypeCheckpoint:
+		r.Checkpoints++
+		var cp Checkpoint
+		if err := json.Unmarshal(e.Record, &cp); err != nil {
+			problem("checkpoint %d is damaged: %v", e.Seq, err)
+			break
+		}
+		if cp.Seq != e.Seq-1 || cp.Head != e.PrevHash {
+			problem("checkpoint %d does not match the record before it", e.Seq)
+			break
+		}
+		if v.opts.Key != nil {
+			if err := cp.verify(v.opts.Key); err != nil {
+				problem("checkpoint %d: %v", e.Seq, err)
+				break
+			}
+			r.Signed++
+		}
+		r.LastCheckpoint, r.LastCheckpointTime = cp.Seq, cp.Time
+		v.sinceCheck = 0
+	case TypeDecision:
+		r.Records++
+		v.sinceCheck++
+	default:
+		problem("record %d has unknown type %q", e.Seq, e.Type)
+	}
+	v.prev = e
+}
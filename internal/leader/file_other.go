@@ -0,0 +1,31 @@
+//go:build !unix
+
+package leader
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// FileBackend elects a leader with an exclusive lock on a local file. File
+// locks are only supported on unix platforms.
+type FileBackend struct {
+	path string
+}
+
+// NewFileBackend creates a backend locking the given file
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path}
+}
+
+// Name returns the backend name
+func (b *FileBackend) Name() string {
+	return "file lock " + b.path
+}
+
+// Acquire always fails on this platform
+func (b *FileBackend) Acquire(ctx context.Context, expires time.Time) (Lease, error) {
+	return nil, trace.NotImplemented("file lock leader election is not supported on this platform")
+}
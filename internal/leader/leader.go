@@ -0,0 +1,135 @@
+package leader
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/status"
+)
+
+// Backends accepted by the leader_election.backend setting
+const (
+	// BackendTeleport uses a Teleport semaphore as the leadership lease
+	BackendTeleport = "teleport"
+	// BackendFile uses a local file lock, for development
+	BackendFile = "file"
+)
+
+// Lease is a held leadership lease
+type Lease interface {
+	// KeepAlive extends the lease until the given time
+	KeepAlive(ctx context.Context, expires time.Time) error
+	// Release gives up the lease
+	Release(ctx context.Context) error
+}
+
+// Backend acquires leadership leases
+type Backend interface {
+	// Acquire tries once to take the lease until the given time. It returns an
+	// error if another holder has it.
+	Acquire(ctx context.Context, expires time.Time) (Lease, error)
+	// Name returns the backend name for logging
+	Name() string
+}
+
+// Elector campaigns for leadership and tracks whether this replica is the leader
+type Elector struct {
+	backend       Backend
+	leaseDuration time.Duration
+	retryInterval time.Duration
+	registry      *status.Registry
+	logger        *slog.Logger
+	leader        atomic.Bool
+}
+
+// NewElector creates a new leader elector reporting into registry
+func NewElector(backend Backend, leaseDuration, retryInterval time.Duration, registry *status.Registry, logger *slog.Logger) *Elector {
+	return &Elector{
+		backend:       backend,
+		leaseDuration: leaseDuration,
+		retryInterval: retryInterval,
+		registry:      registry,
+		logger:        logger,
+	}
+}
+
+// IsLeader reports whether this replica currently holds the lease
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Check returns the status check of the replica's role. Followers only fail
+// it when requireLeader is set, so that they don't block rolling deploys.
+func (e *Elector) Check(requireLeader bool) status.CheckFunc {
+	return func() (bool, string) {
+		if e.IsLeader() {
+			return true, "leader"
+		}
+		return !requireLeader, "follower"
+	}
+}
+
+// Run campaigns for leadership until ctx is cancelled. onChange is called with
+// true when leadership is acquired and with false when it is lost.
+func (e *Elector) Run(ctx context.Context, onChange func(leader bool)) {
+	e.logger.Info("Campaigning for leadership", "backend", e.backend.Name())
+
+	for {
+		lease, err := e.backend.Acquire(ctx, time.Now().Add(e.leaseDuration))
+		if err == nil {
+			e.logger.Info("Acquired leadership")
+			e.leader.Store(true)
+			onChange(true)
+
+			err = e.hold(ctx, lease)
+
+			e.leader.Store(false)
+			onChange(false)
+			if ctx.Err() != nil {
+				e.release(lease)
+				return
+			}
+			e.logger.Warn("Lost leadership", logging.Err(err))
+			e.registry.Error(status.ComponentLeader, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// hold keeps the lease alive until ctx is cancelled or a keep-alive fails
+func (e *Elector) hold(ctx context.Context, lease Lease) error {
+	ticker := time.NewTicker(e.leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := lease.KeepAlive(ctx, time.Now().Add(e.leaseDuration)); err != nil {
+				return trace.Wrap(err, "failed to keep leadership lease alive")
+			}
+		}
+	}
+}
+
+// release gives up the lease on shutdown so a follower can take over immediately
+func (e *Elector) release(lease Lease) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := lease.Release(ctx); err != nil {
+		e.logger.Warn("Failed to release leadership lease", logging.Err(err))
+	} else {
+		e.logger.Info("Released leadership lease")
+	}
+}
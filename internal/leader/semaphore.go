@@ -0,0 +1,78 @@
+package leader
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// SemaphoreKind is the Teleport semaphore kind used for leader election
+const SemaphoreKind = "autoreviewer_leader"
+
+// SemaphoreAPI is the subset of the Teleport API used by the semaphore backend
+type SemaphoreAPI interface {
+	AcquireSemaphore(ctx context.Context, params types.AcquireSemaphoreRequest) (*types.SemaphoreLease, error)
+	KeepAliveSemaphoreLease(ctx context.Context, lease types.SemaphoreLease) error
+	CancelSemaphoreLease(ctx context.Context, lease types.SemaphoreLease) error
+}
+
+// SemaphoreBackend elects a leader with a single-lease Teleport semaphore, so
+// it needs no permissions outside of Teleport
+type SemaphoreBackend struct {
+	api    SemaphoreAPI
+	name   string
+	holder string
+}
+
+// NewSemaphoreBackend creates a backend using the named semaphore
+func NewSemaphoreBackend(api SemaphoreAPI, name, holder string) *SemaphoreBackend {
+	return &SemaphoreBackend{
+		api:    api,
+		name:   name,
+		holder: holder,
+	}
+}
+
+// Name returns the backend name
+func (b *SemaphoreBackend) Name() string {
+	return "teleport semaphore " + b.name
+}
+
+// Acquire tries to take the only lease of the semaphore
+func (b *SemaphoreBackend) Acquire(ctx context.Context, expires time.Time) (Lease, error) {
+	lease, err := b.api.AcquireSemaphore(ctx, types.AcquireSemaphoreRequest{
+		SemaphoreKind: SemaphoreKind,
+		SemaphoreName: b.name,
+		MaxLeases:     1,
+		Expires:       expires,
+		Holder:        b.holder,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &semaphoreLease{api: b.api, lease: *lease}, nil
+}
+
+// semaphoreLease is a held semaphore lease
+type semaphoreLease struct {
+	api   SemaphoreAPI
+	lease types.SemaphoreLease
+}
+
+// KeepAlive extends the semaphore lease
+func (l *semaphoreLease) KeepAlive(ctx context.Context, expires time.Time) error {
+	lease := l.lease
+	lease.Expires = expires
+	if err := l.api.KeepAliveSemaphoreLease(ctx, lease); err != nil {
+		return trace.Wrap(err)
+	}
+	l.lease = lease
+	return nil
+}
+
+// Release cancels the semaphore lease
+func (l *semaphoreLease) Release(ctx context.Context) error {
+	return trace.Wrap(l.api.CancelSemaphoreLease(ctx, l.lease))
+}
@@ -0,0 +1,67 @@
+// Package logging builds the service's structured logger and defines the
+// field names shared by every component, so log pipelines can index them
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/redact"
+)
+
+// Output formats accepted by the log.format setting
+const (
+	// FormatText writes logfmt-style key=value lines
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line
+	FormatJSON = "json"
+)
+
+// Field names used consistently across components
+const (
+	// KeyRequestID is the access request ID
+	KeyRequestID = "request_id"
+	// KeyUser is the user who created the access request
+	KeyUser = "user"
+	// KeyRule is the name of the rejection or SLA rule involved
+	KeyRule = "rule"
+	// KeyDecisionID identifies a single evaluation of a request
+	KeyDecisionID = "decision_id"
+	// KeyTraceID is the OpenTelemetry trace ID of the request's evaluation
+	KeyTraceID = "trace_id"
+	// KeyOutcome is the outcome of a decision
+	KeyOutcome = "outcome"
+	// KeySource is how the request reached the evaluation pipeline
+	KeySource = "source"
+	// KeyError is the error being reported
+	KeyError = "error"
+)
+
+// New creates a logger writing to w in the given format at the given level.
+// Every record is redacted by redactor first.
+func New(w io.Writer, format, level string, redactor *redact.Redactor) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, trace.BadParameter("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, trace.BadParameter("unknown log format %q, expected %q or %q", format, FormatText, FormatJSON)
+	}
+	return slog.New(redact.NewHandler(handler, redactor)), nil
+}
+
+// Err returns the attribute for an error
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
@@ -0,0 +1,250 @@
+// Package metrics defines the Prometheus metrics exported by the service
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "teleport_autoreviewer"
+
+var (
+	// Decisions counts processed requests by outcome and the rule that decided them
+	Decisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "decisions_total",
+		Help:      "Access requests processed, by outcome and rule.",
+	}, []string{"outcome", "rule"})
+
+	// EvaluationDuration observes how long deciding a single request takes,
+	// including the review call
+	EvaluationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "evaluation_duration_seconds",
+		Help:      "Time taken to evaluate and decide a single access request.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// APIDuration observes Teleport API call latency by method
+	APIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "teleport_api_duration_seconds",
+		Help:      "Latency of Teleport API calls, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// APIErrors counts failed Teleport API calls by method
+	APIErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "teleport_api_errors_total",
+		Help:      "Failed Teleport API calls, by method.",
+	}, []string{"method"})
+
+	// WatcherReconnects counts access request watchers re-established after a failure
+	WatcherReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "watcher_reconnects_total",
+		Help:      "Access request watcher failures followed by a reconnection attempt.",
+	})
+
+	// QueueDepth is the number of requests waiting for a worker
+	QueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Access requests queued for evaluation.",
+	})
+
+	// QueueDropped counts requests dropped because the queue was full
+	QueueDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_dropped_total",
+		Help:      "Access requests dropped because the queue was full.",
+	})
+
+	// IdentityExpiry is when the current identity's certificate expires
+	IdentityExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "identity_expiry_timestamp_seconds",
+		Help:      "Unix time at which the Teleport identity certificate expires.",
+	})
+
+	// LastEvent is when the last access request event or poll result was received
+	LastEvent = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_event_timestamp_seconds",
+		Help:      "Unix time of the last access request event received.",
+	})
+
+	// LastProbe is when the Teleport connection was last probed successfully
+	LastProbe = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_probe_timestamp_seconds",
+		Help:      "Unix time of the last successful probe of a silent watch stream.",
+	})
+
+	// WatchStale reports whether the watch stream is silent without a successful probe
+	WatchStale = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "watch_stale",
+		Help:      "Whether the watch stream has been silent past the stale threshold without a successful probe.",
+	})
+
+	// SLAEscalations counts requests escalated for exceeding an SLA rule's max age
+	SLAEscalations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sla_escalations_total",
+		Help:      "Pending requests escalated for exceeding an SLA rule's max age, by rule.",
+	}, []string{"rule"})
+
+	// SLAEscalated is the number of escalated requests still pending
+	SLAEscalated = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sla_escalated_requests",
+		Help:      "Escalated requests that are still pending.",
+	})
+
+	// ReconcileDrift counts pending requests found by reconciliation that the
+	// watch loop missed
+	ReconcileDrift = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_drift_total",
+		Help:      "Undecided pending requests found by reconciliation sweeps.",
+	})
+
+	// ReconcileTimestamp is when the last reconciliation sweep completed
+	ReconcileTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconcile_last_sweep_timestamp_seconds",
+		Help:      "Unix time of the last completed reconciliation sweep.",
+	})
+
+	// ReloadSuccess reports whether the last rule reload succeeded
+	ReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_successful",
+		Help:      "Whether the last rule reload succeeded (1) or failed (0).",
+	})
+
+	// ReloadTimestamp is when rules were last reloaded
+	ReloadTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_timestamp_seconds",
+		Help:      "Unix time of the last rule reload attempt.",
+	})
+
+	// JournalRecords counts decisions written to the journal
+	JournalRecords = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "journal_records_total",
+		Help:      "Decisions written to the decision journal.",
+	})
+
+	// JournalErrors counts decisions that could not be written to the journal
+	JournalErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "journal_errors_total",
+		Help:      "Decisions that could not be written to the decision journal.",
+	})
+
+	// JournalRotations counts journal files rotated
+	JournalRotations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "journal_rotations_total",
+		Help:      "Decision journal files rotated by size or age.",
+	})
+
+	// JournalCheckpoints counts checkpoints written to the journal
+	JournalCheckpoints = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "journal_checkpoints_total",
+		Help:      "Checkpoints sealing the decision journal's hash chain.",
+	})
+
+	// JournalCheckpointSeq is the last record covered by a journal checkpoint
+	JournalCheckpointSeq = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "journal_last_checkpoint_seq",
+		Help:      "Sequence number of the last record covered by a decision journal checkpoint.",
+	})
+
+	// JournalCheckpointTimestamp is when the last journal checkpoint was written
+	JournalCheckpointTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "journal_last_checkpoint_timestamp_seconds",
+		Help:      "Unix time of the last decision journal checkpoint.",
+	})
+
+	// RulesInfo carries the version of the rules in effect as a label
+	RulesInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rules_info",
+		Help:      "Version of the rule sets in effect, always 1.",
+	}, []string{"version"})
+)
+
+// Registry holds every metric of the service along with Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Decisions,
+		EvaluationDuration,
+		APIDuration,
+		APIErrors,
+		WatcherReconnects,
+		QueueDepth,
+		QueueDropped,
+		IdentityExpiry,
+		LastEvent,
+		LastProbe,
+		WatchStale,
+		SLAEscalations,
+		SLAEscalated,
+		ReconcileDrift,
+		ReconcileTimestamp,
+		ReloadSuccess,
+		ReloadTimestamp,
+		RulesInfo,
+		JournalRecords,
+		JournalErrors,
+		JournalRotations,
+		JournalCheckpoints,
+		JournalCheckpointSeq,
+		JournalCheckpointTimestamp,
+	)
+	ReloadSuccess.Set(1)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveAPI records the latency and outcome of a Teleport API call started at start
+func ObserveAPI(method string, start time.Time, err error) {
+	APIDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		APIErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// SetRulesVersion replaces the version reported by RulesInfo
+func SetRulesVersion(version string) {
+	RulesInfo.Reset()
+	RulesInfo.WithLabelValues(version).Set(1)
+}
+
+// SetTimestamp sets a timestamp gauge to t, or to zero if t is unset
+func SetTimestamp(g prometheus.Gauge, t time.Time) {
+	if t.IsZero() {
+		g.Set(0)
+		return
+	}
+	g.Set(float64(t.UnixNano()) / 1e9)
+}
@@ -0,0 +1,75 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ReasonKey is the log attribute holding request reasons, which get the
+// full reason redaction instead of only the patterns
+const ReasonKey = "reason"
+
+// Handler wraps a slog.Handler and redacts the message and every string or
+// error attribute of each record before passing it on
+type Handler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// NewHandler returns a handler redacting records before they reach next
+func NewHandler(next slog.Handler, redactor *Redactor) *Handler {
+	return &Handler{next: next, redactor: redactor}
+}
+
+// Enabled reports whether the wrapped handler handles records at level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record and passes it to the wrapped handler
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactor.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacts attrs once and adds them to the wrapped handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, h.attr(a))
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+// WithGroup opens a group on the wrapped handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// attr redacts a single attribute
+func (h *Handler) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if a.Key == ReasonKey {
+			return slog.String(a.Key, h.redactor.Reason(v.String()))
+		}
+		return slog.String(a.Key, h.redactor.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, 0, len(group))
+		for _, ga := range group {
+			redacted = append(redacted, h.attr(ga))
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.redactor.String(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,77 @@
+// Package redact removes sensitive data from text produced from access
+// requests before it is logged or exported
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/config"
+)
+
+// Redactor applies the configured redaction to strings and request reasons.
+// A nil Redactor leaves text unchanged.
+type Redactor struct {
+	patterns  []pattern
+	maxLength int
+	hash      bool
+}
+
+type pattern struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// New compiles the redaction settings
+func New(cfg *config.Config) (*Redactor, error) {
+	r := &Redactor{
+		maxLength: cfg.Redaction.MaxReasonLength,
+		hash:      cfg.Redaction.HashReasons,
+	}
+	for _, p := range cfg.Redaction.Patterns {
+		regex, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to compile redaction pattern %s", p.Name)
+		}
+		replacement := p.Replacement
+		if replacement == "" {
+			replacement = fmt.Sprintf("[REDACTED:%s]", p.Name)
+		}
+		r.patterns = append(r.patterns, pattern{regex: regex, replacement: replacement})
+	}
+	return r, nil
+}
+
+// String replaces every match of the redaction patterns in s
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.regex.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// Reason redacts a request reason. With hashing enabled the reason is
+// replaced by a digest that still lets identical reasons be correlated,
+// otherwise the patterns are applied and the result is truncated.
+func (r *Redactor) Reason(reason string) string {
+	if r == nil || reason == "" {
+		return reason
+	}
+	if r.hash {
+		sum := sha256.Sum256([]byte(reason))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	}
+
+	reason = r.String(reason)
+	if runes := []rune(reason); r.maxLength > 0 && len(runes) > r.maxLength {
+		reason = fmt.Sprintf("%s...(%d more characters)", string(runes[:r.maxLength]), len(runes)-r.maxLength)
+	}
+	return reason
+}
@@ -0,0 +1,80 @@
+package shard
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// memberSuffix marks heartbeat files in the membership directory
+const memberSuffix = ".member"
+
+// FileStore tracks members as heartbeat files in a local directory. Each
+// file's modification time is set to its expiry. It only coordinates processes
+// sharing the directory and is meant for development.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in the given directory
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Name returns the store name
+func (s *FileStore) Name() string {
+	return "directory " + s.dir
+}
+
+// Heartbeat creates or touches the member's file
+func (s *FileStore) Heartbeat(ctx context.Context, member string, expires time.Time) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	path := s.path(member)
+	if err := os.WriteFile(path, []byte(member), 0o600); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Chtimes(path, expires, expires))
+}
+
+// Members returns the members whose files have not expired
+func (s *FileStore) Members(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	now := time.Now()
+	var members []string
+	for _, entry := range entries {
+		member, ok := strings.CutSuffix(entry.Name(), memberSuffix)
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Before(now) {
+			continue
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// Leave removes the member's file
+func (s *FileStore) Leave(ctx context.Context, member string) error {
+	err := os.Remove(s.path(member))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return trace.ConvertSystemError(err)
+}
+
+// path returns the member's heartbeat file
+func (s *FileStore) path(member string) string {
+	return filepath.Join(s.dir, member+memberSuffix)
+}
@@ -0,0 +1,63 @@
+package shard
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strconv"
+)
+
+// Ring is a consistent hash ring assigning keys to members. Each member is
+// placed on the ring several times so keys spread evenly, and removing a
+// member only moves the keys it owned.
+type Ring struct {
+	points  []uint32
+	owners  map[uint32]string
+	members []string
+}
+
+// NewRing builds a ring from the members with vnodes points per member
+func NewRing(members []string, vnodes int) *Ring {
+	r := &Ring{
+		owners:  make(map[uint32]string, len(members)*vnodes),
+		members: slices.Sorted(slices.Values(members)),
+	}
+	for _, member := range r.members {
+		for i := 0; i < vnodes; i++ {
+			point := hash(member + "#" + strconv.Itoa(i))
+			// On the rare collision the lowest member name wins, so every
+			// replica builds the same ring
+			if _, ok := r.owners[point]; ok {
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+	slices.Sort(r.points)
+	return r
+}
+
+// Owner returns the member owning the key, or an empty string for an empty ring
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i, _ := slices.BinarySearch(r.points, h)
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// Members returns the sorted ring members
+func (r *Ring) Members() []string {
+	return r.members
+}
+
+// hash places a string on the ring
+func hash(s string) uint32 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint32(sum[:4])
+}
@@ -0,0 +1,120 @@
+package shard
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// SemaphoreKind is the Teleport semaphore kind used for shard membership
+const SemaphoreKind = "autoreviewer_shard"
+
+// maxMembers bounds the size of the group, it is the semaphore's lease limit
+const maxMembers = 1024
+
+// SemaphoreAPI is the subset of the Teleport API used by the semaphore store
+type SemaphoreAPI interface {
+	AcquireSemaphore(ctx context.Context, params types.AcquireSemaphoreRequest) (*types.SemaphoreLease, error)
+	KeepAliveSemaphoreLease(ctx context.Context, lease types.SemaphoreLease) error
+	CancelSemaphoreLease(ctx context.Context, lease types.SemaphoreLease) error
+	GetSemaphores(ctx context.Context, filter types.SemaphoreFilter) ([]types.Semaphore, error)
+}
+
+// SemaphoreStore tracks members as leases on a shared Teleport semaphore. Each
+// replica holds one lease, and the lease holders are the members.
+type SemaphoreStore struct {
+	api  SemaphoreAPI
+	name string
+
+	mu    sync.Mutex
+	lease *types.SemaphoreLease
+}
+
+// NewSemaphoreStore creates a store using the named semaphore
+func NewSemaphoreStore(api SemaphoreAPI, name string) *SemaphoreStore {
+	return &SemaphoreStore{
+		api:  api,
+		name: name,
+	}
+}
+
+// Name returns the store name
+func (s *SemaphoreStore) Name() string {
+	return "teleport semaphore " + s.name
+}
+
+// Heartbeat keeps this replica's lease alive, acquiring a new one if it was lost
+func (s *SemaphoreStore) Heartbeat(ctx context.Context, member string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lease != nil {
+		lease := *s.lease
+		lease.Expires = expires
+		err := s.api.KeepAliveSemaphoreLease(ctx, lease)
+		if err == nil {
+			s.lease = &lease
+			return nil
+		}
+		if !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		// The lease expired, acquire a new one below
+		s.lease = nil
+	}
+
+	lease, err := s.api.AcquireSemaphore(ctx, types.AcquireSemaphoreRequest{
+		SemaphoreKind: SemaphoreKind,
+		SemaphoreName: s.name,
+		MaxLeases:     maxMembers,
+		Expires:       expires,
+		Holder:        member,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	s.lease = lease
+	return nil
+}
+
+// Members returns the holders of unexpired leases, sorted. A replica
+// restarted with the same identity holds two leases until the old one
+// expires, but is listed once.
+func (s *SemaphoreStore) Members(ctx context.Context) ([]string, error) {
+	sems, err := s.api.GetSemaphores(ctx, types.SemaphoreFilter{
+		SemaphoreKind: SemaphoreKind,
+		SemaphoreName: s.name,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	now := time.Now()
+	var members []string
+	for _, sem := range sems {
+		for _, ref := range sem.LeaseRefs() {
+			if ref.Expires.After(now) {
+				members = append(members, ref.Holder)
+			}
+		}
+	}
+	slices.Sort(members)
+	return slices.Compact(members), nil
+}
+
+// Leave cancels this replica's lease
+func (s *SemaphoreStore) Leave(ctx context.Context, member string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lease == nil {
+		return nil
+	}
+	err := s.api.CancelSemaphoreLease(ctx, *s.lease)
+	s.lease = nil
+	return trace.Wrap(err)
+}
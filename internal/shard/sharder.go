@@ -0,0 +1,162 @@
+package shard
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+)
+
+// Backends accepted by the sharding.backend setting
+const (
+	// BackendTeleport tracks members as leases on a Teleport semaphore
+	BackendTeleport = "teleport"
+	// BackendFile tracks members as heartbeat files in a local directory, for development
+	BackendFile = "file"
+)
+
+// Store tracks the live members of the replica group
+type Store interface {
+	// Heartbeat registers the member or renews its registration until expires
+	Heartbeat(ctx context.Context, member string, expires time.Time) error
+	// Members returns the members whose registration has not expired
+	Members(ctx context.Context) ([]string, error)
+	// Leave removes the member's registration
+	Leave(ctx context.Context, member string) error
+	// Name returns the store name for logging
+	Name() string
+}
+
+// Sharder splits requests between replicas by consistent hashing of the
+// request ID over the live members of the group
+type Sharder struct {
+	store         Store
+	self          string
+	vnodes        int
+	leaseDuration time.Duration
+	interval      time.Duration
+	logger        *slog.Logger
+
+	mu   sync.RWMutex
+	ring *Ring
+	// lastHeartbeat is when the last successful heartbeat started. Peers
+	// drop this replica from their rings once its lease expires, so it must
+	// stop owning requests by then too.
+	lastHeartbeat time.Time
+}
+
+// NewSharder creates a sharder for this replica
+func NewSharder(store Store, self string, vnodes int, leaseDuration, interval time.Duration, logger *slog.Logger) *Sharder {
+	return &Sharder{
+		store:         store,
+		self:          self,
+		vnodes:        vnodes,
+		leaseDuration: leaseDuration,
+		interval:      interval,
+		logger:        logger,
+	}
+}
+
+// Owns reports whether this replica is responsible for the key. Nothing is
+// owned until membership has been read for the first time, nor after the
+// lease could not be renewed before it expired.
+func (s *Sharder) Owns(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ring != nil && s.ring.Owner(key) == s.self
+}
+
+// Members returns the current members of the group
+func (s *Sharder) Members() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.ring == nil {
+		return nil
+	}
+	return slices.Clone(s.ring.Members())
+}
+
+// Run heartbeats and refreshes membership until ctx is cancelled. onChange is
+// called whenever the set of members, and so the ownership of requests, changes.
+func (s *Sharder) Run(ctx context.Context, onChange func(members []string)) {
+	s.logger.Info("Joining shard group", "identity", s.self, "backend", s.store.Name())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.refresh(ctx, onChange); err != nil {
+			s.logger.Warn("Failed to refresh shard membership", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.leave()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh renews this replica's registration and rebuilds the ring if the
+// members changed
+func (s *Sharder) refresh(ctx context.Context, onChange func(members []string)) error {
+	start := time.Now()
+	if err := s.store.Heartbeat(ctx, s.self, start.Add(s.leaseDuration)); err != nil {
+		s.expire(onChange)
+		return trace.Wrap(err, "failed to heartbeat")
+	}
+	s.mu.Lock()
+	s.lastHeartbeat = start
+	s.mu.Unlock()
+
+	members, err := s.store.Members(ctx)
+	if err != nil {
+		return trace.Wrap(err, "failed to list members")
+	}
+	if !slices.Contains(members, s.self) {
+		members = append(members, s.self)
+	}
+	slices.Sort(members)
+
+	s.mu.Lock()
+	if s.ring != nil && slices.Equal(s.ring.Members(), members) {
+		s.mu.Unlock()
+		return nil
+	}
+	s.ring = NewRing(members, s.vnodes)
+	s.mu.Unlock()
+
+	s.logger.Info("Shard membership changed", "members", members)
+	onChange(members)
+	return nil
+}
+
+// expire drops the ring once the lease has expired without being renewed,
+// so this replica owns nothing while its peers have taken over its requests
+func (s *Sharder) expire(onChange func(members []string)) {
+	s.mu.Lock()
+	if s.ring == nil || time.Since(s.lastHeartbeat) < s.leaseDuration {
+		s.mu.Unlock()
+		return
+	}
+	s.ring = nil
+	s.mu.Unlock()
+
+	s.logger.Warn("Shard lease expired, owning no requests until it is renewed", "lease_duration", s.leaseDuration)
+	onChange(nil)
+}
+
+// leave removes this replica's registration on shutdown so peers rebalance immediately
+func (s *Sharder) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.store.Leave(ctx, s.self); err != nil {
+		s.logger.Warn("Failed to leave shard group", logging.Err(err))
+	}
+}
@@ -0,0 +1,49 @@
+package status
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"teleport-autoreviewer/internal/logging"
+)
+
+// HealthHandler serves the detailed report of every check. It fails with 503
+// only when the service is unhealthy.
+func (r *Registry) HealthHandler(logger *slog.Logger) http.Handler {
+	return r.handler(logger, r.DetailedReport)
+}
+
+// ProbeHandler serves the report of the given kinds of checks, failing with
+// 503 when any of them fails
+func (r *Registry) ProbeHandler(logger *slog.Logger, kinds ...Kind) http.Handler {
+	return r.handler(logger, func() Report {
+		report := r.Report(kinds...)
+		if report.Status == StatusDegraded {
+			report.Status = StatusUnhealthy
+		}
+		return report
+	})
+}
+
+// handler serves the report built by build as JSON
+func (r *Registry) handler(logger *slog.Logger, build func() Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		report := build()
+		code := http.StatusOK
+		if report.Status == StatusUnhealthy {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			logger.Error("Failed to encode status report", "path", req.URL.Path, logging.Err(err))
+		}
+	})
+}
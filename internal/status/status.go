@@ -0,0 +1,221 @@
+// Package status collects the state of the service's components into a
+// single registry that the health, liveness and readiness endpoints report
+package status
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Components reporting into the registry
+const (
+	ComponentProcess     = "process"
+	ComponentWatchLoop   = "watch_loop"
+	ComponentWorkers     = "workers"
+	ComponentWatcher     = "watcher"
+	ComponentStream      = "stream"
+	ComponentRules       = "rules"
+	ComponentIdentity    = "identity"
+	ComponentPermissions = "permissions"
+	ComponentLeader      = "leader"
+	ComponentJournal     = "journal"
+)
+
+// Kind decides which endpoints a component's check counts towards
+type Kind string
+
+const (
+	// KindLiveness checks fail when the process is stuck and should be restarted
+	KindLiveness Kind = "liveness"
+	// KindReadiness checks fail when the service can't review requests
+	KindReadiness Kind = "readiness"
+	// KindInfo checks only degrade the service, since a restart won't fix them
+	KindInfo Kind = "info"
+)
+
+// Overall statuses
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// CheckFunc computes a component's state each time the status is read
+type CheckFunc func() (ok bool, message string)
+
+// Check is the state of a single component
+type Check struct {
+	Name    string `json:"name"`
+	Kind    Kind   `json:"kind"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+	// LastError is the most recent error reported by the component, even if
+	// it has recovered since
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+}
+
+// Report is the status of the service as served by every endpoint
+type Report struct {
+	Status  string         `json:"status"`
+	Uptime  string         `json:"uptime"`
+	Checks  []Check        `json:"checks"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// component is the registered state of a component
+type component struct {
+	kind          Kind
+	check         CheckFunc
+	ok            bool
+	message       string
+	lastError     string
+	lastErrorTime time.Time
+}
+
+// Registry holds the state components report. Components either push their
+// state with Set or register a CheckFunc evaluated on every read, and report
+// errors with Error.
+type Registry struct {
+	mu         sync.RWMutex
+	start      time.Time
+	order      []string
+	components map[string]*component
+	details    map[string]func() any
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		start:      time.Now(),
+		components: make(map[string]*component),
+		details:    make(map[string]func() any),
+	}
+}
+
+// Register adds a component whose state is computed by check
+func (r *Registry) Register(name string, kind Kind, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comp := r.component(name)
+	comp.kind = kind
+	comp.check = check
+}
+
+// Set records the state of a component
+func (r *Registry) Set(name string, kind Kind, ok bool, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comp := r.component(name)
+	comp.kind = kind
+	comp.ok = ok
+	comp.message = message
+}
+
+// Error records the last error of a component. It doesn't change whether the
+// component's check passes.
+func (r *Registry) Error(name string, err error) {
+	if err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comp := r.component(name)
+	comp.lastError = err.Error()
+	comp.lastErrorTime = time.Now()
+}
+
+// Details adds a section to the detailed health report
+func (r *Registry) Details(name string, fn func() any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.details[name] = fn
+}
+
+// component returns the named component, adding it if needed. Must be
+// called with the lock held.
+func (r *Registry) component(name string) *component {
+	comp, ok := r.components[name]
+	if !ok {
+		comp = &component{kind: KindInfo}
+		r.components[name] = comp
+		r.order = append(r.order, name)
+	}
+	return comp
+}
+
+// Checks returns the checks of the given kinds, or of every component if no
+// kind is given, in registration order
+func (r *Registry) Checks(kinds ...Kind) []Check {
+	var checks []Check
+	var funcs []CheckFunc
+
+	r.mu.RLock()
+	for _, name := range r.order {
+		comp := r.components[name]
+		if len(kinds) > 0 && !slices.Contains(kinds, comp.kind) {
+			continue
+		}
+
+		check := Check{Name: name, Kind: comp.kind, OK: comp.ok, Message: comp.message, LastError: comp.lastError}
+		if !comp.lastErrorTime.IsZero() {
+			errTime := comp.lastErrorTime
+			check.LastErrorTime = &errTime
+		}
+		checks = append(checks, check)
+		funcs = append(funcs, comp.check)
+	}
+	r.mu.RUnlock()
+
+	// Check functions take component locks, which may be held by components
+	// reporting into the registry, so they run without the registry lock
+	for i, fn := range funcs {
+		if fn != nil {
+			checks[i].OK, checks[i].Message = fn()
+		}
+	}
+	return checks
+}
+
+// Report builds the report of the given kinds of checks. The service is
+// unhealthy if a liveness or readiness check fails and degraded if only
+// other checks fail.
+func (r *Registry) Report(kinds ...Kind) Report {
+	report := Report{
+		Status: StatusHealthy,
+		Uptime: time.Since(r.start).Round(time.Second).String(),
+		Checks: r.Checks(kinds...),
+	}
+	for _, check := range report.Checks {
+		switch {
+		case check.OK:
+		case check.Kind == KindInfo:
+			if report.Status == StatusHealthy {
+				report.Status = StatusDegraded
+			}
+		default:
+			report.Status = StatusUnhealthy
+		}
+	}
+	return report
+}
+
+// DetailedReport builds the report of every check along with the details
+// sections
+func (r *Registry) DetailedReport() Report {
+	report := r.Report()
+
+	r.mu.RLock()
+	fns := maps.Clone(r.details)
+	r.mu.RUnlock()
+
+	if len(fns) > 0 {
+		report.Details = make(map[string]any, len(fns))
+		for name, fn := range fns {
+			report.Details[name] = fn()
+		}
+	}
+	return report
+}
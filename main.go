@@ -2,14 +2,24 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
 	"teleport-autoreviewer/config"
+	"teleport-autoreviewer/internal/journal"
+	"teleport-autoreviewer/internal/leader"
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/redact"
+	"teleport-autoreviewer/internal/shard"
+	"teleport-autoreviewer/internal/status"
+	"teleport-autoreviewer/internal/tracing"
 	"teleport-autoreviewer/server"
 	"teleport-autoreviewer/teleport"
 
@@ -18,41 +28,114 @@ import (
 )
 
 func main() {
-	logger := log.New(os.Stdout, "[teleport-autoreviewer] ", log.LstdFlags|log.Lshortfile)
-	logger.Println("Starting Teleport Auto-reviewer")
+	if len(os.Args) > 1 && os.Args[1] == "verify-journal" {
+		os.Exit(verifyJournal(os.Args[2:], os.Stdout))
+	}
+
+	configPath := flag.String("config", "config.yaml", "path to the configuration file")
+	flag.Parse()
 
-	if err := run(logger); err != nil {
-		logger.Printf("error: %v", err)
+	// Used until the configured logger is set up
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	logger.Info("Starting Teleport Auto-reviewer")
+
+	if err := run(*configPath, logger); err != nil {
+		logger.Error("Teleport Auto-reviewer failed", logging.Err(err))
 		os.Exit(1)
 	}
 }
 
-func run(logger *log.Logger) error {
+func run(configPath string, logger *slog.Logger) error {
 	// Load configuration
-	cfg, err := loadConfig("config.yaml")
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	logger.Printf("Loaded configuration with %d rejection rules", len(cfg.Rejection.Rules))
+	redactor, err := redact.New(cfg)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	logger, err = logging.New(os.Stdout, cfg.Log.Format, cfg.Log.Level, redactor)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	slog.SetDefault(logger)
+
+	logger.Info("Loaded configuration", "path", configPath, "rejection_rules", len(cfg.Rejection.Rules))
 
 	// Create context that can be cancelled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Set up tracing before the client so its first calls are traced
+	shutdownTracing, err := tracing.Setup(ctx, cfg)
+	if err != nil {
+		return trace.Wrap(err, "failed to set up tracing")
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Warn("Failed to flush traces", logging.Err(err))
+		}
+	}()
+	if cfg.Tracing.Enabled {
+		logger.Info("Tracing enabled", "endpoint", cfg.Tracing.Endpoint, "sample_ratio", *cfg.Tracing.SampleRatio)
+	}
+
+	// Components report their state into the registry served by the health server
+	registry := status.NewRegistry()
+	registry.Set(status.ComponentProcess, status.KindLiveness, true, "running")
+
 	// Create Teleport client
-	client, err := teleport.New(ctx, cfg, logger)
+	client, err := teleport.New(ctx, cfg, registry, logger)
 	if err != nil {
 		return trace.Wrap(err, "failed to create Teleport client")
 	}
 
-	// Create health server
-	healthServer := server.NewHealthServer(
-		cfg.Server.HealthPort,
-		cfg.Server.HealthPath,
-		client,
-		logger,
-	)
+	// Persist every decision if the journal is enabled
+	var decisionJournal *journal.Journal
+	if cfg.Journal.Enabled {
+		opts := journal.Options{
+			Path:       cfg.Journal.Path,
+			MaxSize:    int64(cfg.Journal.MaxSizeMB) << 20,
+			MaxAge:     cfg.Journal.MaxAge,
+			MaxBackups: cfg.Journal.MaxBackups,
+			Sync:       cfg.Journal.Sync,
+		}
+		if opts.SigningKey, err = journal.LoadSigningKey(cfg.Journal.SigningKeyFile); err != nil {
+			return trace.Wrap(err)
+		}
+		decisionJournal, err = journal.Open(opts, registry, logger)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer func() {
+			if err := decisionJournal.Close(); err != nil {
+				logger.Warn("Failed to close decision journal", logging.Err(err))
+			}
+		}()
+		client.SetJournal(decisionJournal)
+		logger.Info("Decision journal enabled", "path", cfg.Journal.Path,
+			"max_size_mb", cfg.Journal.MaxSizeMB, "max_age", cfg.Journal.MaxAge, "max_backups", cfg.Journal.MaxBackups,
+			"checkpoint_interval", cfg.Journal.CheckpointInterval)
+	}
+
+	// Create health server, serving the admin API if enabled
+	var admin *server.AdminAPI
+	if cfg.Admin.Enabled {
+		token, err := server.LoadToken(cfg.Admin.TokenFile)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		admin = server.NewAdminAPI(client, registry, newReloader(client, configPath), token, logger)
+		logger.Info("Admin API enabled", "decision_history", cfg.Admin.DecisionHistory, "listen_address", cfg.Admin.ListenAddress)
+	}
+	healthServer, err := server.NewHealthServer(cfg, registry, admin, logger)
+	if err != nil {
+		return trace.Wrap(err, "failed to create health server")
+	}
 
 	// Create a wait group for goroutines
 	var wg sync.WaitGroup
@@ -62,7 +145,7 @@ func run(logger *log.Logger) error {
 	go func() {
 		defer wg.Done()
 		if err := healthServer.Start(ctx); err != nil {
-			logger.Printf("Health server error: %v", err)
+			logger.Error("Health server failed", logging.Err(err))
 		}
 	}()
 
@@ -73,7 +156,101 @@ func run(logger *log.Logger) error {
 			defer wg.Done()
 			runIdentityRefresh(ctx, client, cfg.Teleport.IdentityRefreshInterval, logger)
 		}()
-		logger.Printf("Identity refresh enabled with interval: %v", cfg.Teleport.IdentityRefreshInterval)
+		logger.Info("Identity refresh enabled", "interval", cfg.Teleport.IdentityRefreshInterval)
+	}
+
+	// Start evaluation workers
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client.RunWorkers(ctx)
+	}()
+
+	// Start reconciliation sweep if configured
+	if cfg.Reconciliation.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runReconciliation(ctx, client, cfg.Reconciliation.Interval, logger)
+		}()
+		logger.Info("Reconciliation sweep enabled", "interval", cfg.Reconciliation.Interval)
+	}
+
+	// Start pending-request SLA sweeper if configured
+	if cfg.SLA.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runSLASweep(ctx, client, cfg.SLA.Interval, logger)
+		}()
+		logger.Info("SLA sweeper enabled", "interval", cfg.SLA.Interval)
+	}
+
+	// Start leader election if configured
+	if cfg.LeaderElection.Enabled {
+		elector := leader.NewElector(
+			newLeaderBackend(cfg, client),
+			cfg.LeaderElection.LeaseDuration,
+			cfg.LeaderElection.RetryInterval,
+			registry,
+			logger,
+		)
+		registry.Register(status.ComponentLeader, status.KindReadiness, elector.Check(cfg.Server.ReadyRequiresLeader))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			elector.Run(ctx, func(isLeader bool) {
+				client.SetLeader(ctx, isLeader)
+			})
+		}()
+		logger.Info("Leader election enabled", "identity", cfg.LeaderElection.Identity)
+	}
+
+	// Start sharding if configured
+	if cfg.Sharding.Enabled {
+		sharder := shard.NewSharder(
+			newShardStore(cfg, client),
+			cfg.Sharding.Identity,
+			cfg.Sharding.VirtualNodes,
+			cfg.Sharding.LeaseDuration,
+			cfg.Sharding.RefreshInterval,
+			logger,
+		)
+		client.SetSharder(sharder)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sharder.Run(ctx, func(members []string) {
+				client.Rebalance(ctx)
+			})
+		}()
+		logger.Info("Sharding enabled", "identity", cfg.Sharding.Identity)
+	}
+
+	// Start periodic rule reload if configured
+	if cfg.Reload.Interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runRuleReload(ctx, client, configPath, cfg.Reload.Interval, logger)
+		}()
+		logger.Info("Rule reload enabled", "interval", cfg.Reload.Interval)
+	}
+
+	// Probe the connection while the watch stream is silent
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runWatchProbe(ctx, client, cfg.Watch.ProbeInterval, logger)
+	}()
+
+	// Seal the decision journal with a checkpoint periodically
+	if decisionJournal != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runJournalCheckpoints(ctx, decisionJournal, cfg.Journal.CheckpointInterval, logger)
+		}()
 	}
 
 	// Start access request watcher
@@ -81,20 +258,31 @@ func run(logger *log.Logger) error {
 	go func() {
 		defer wg.Done()
 		if err := client.WatchAccessRequests(ctx); err != nil {
-			logger.Printf("Access request watcher error: %v", err)
+			logger.Error("Access request watcher failed", logging.Err(err))
 		}
 	}()
 
 	// Setup signal handling
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	logger.Println("Teleport Auto-reviewer started successfully")
-	logger.Printf("Health endpoint available at http://localhost:%d%s", cfg.Server.HealthPort, cfg.Server.HealthPath)
+	scheme := "http"
+	if cfg.Server.TLS.CertFile != "" {
+		scheme = "https"
+	}
+	logger.Info("Teleport Auto-reviewer started successfully",
+		"health_endpoint", fmt.Sprintf("%s://localhost:%d%s", scheme, cfg.Server.HealthPort, cfg.Server.HealthPath),
+		"metrics_endpoint", fmt.Sprintf("%s://localhost:%d%s", scheme, cfg.Server.HealthPort, cfg.Server.MetricsPath))
 
-	// Wait for shutdown signal
-	<-sigCh
-	logger.Println("Received shutdown signal")
+	// Reload rules on SIGHUP until a shutdown signal arrives
+	for sig := range sigCh {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		logger.Info("Received SIGHUP, reloading rules")
+		reloadRules(ctx, client, configPath, logger)
+	}
+	logger.Info("Received shutdown signal")
 
 	// Cancel context to signal all goroutines to stop
 	cancel()
@@ -112,16 +300,16 @@ func run(logger *log.Logger) error {
 
 	select {
 	case <-done:
-		logger.Println("All services stopped gracefully")
+		logger.Info("All services stopped gracefully")
 	case <-shutdownCtx.Done():
-		logger.Println("Shutdown timeout reached, forcing exit")
+		logger.Warn("Shutdown timeout reached, forcing exit")
 	}
 
 	return nil
 }
 
 // runIdentityRefresh runs the identity refresh routine
-func runIdentityRefresh(ctx context.Context, client *teleport.Client, interval time.Duration, logger *log.Logger) {
+func runIdentityRefresh(ctx context.Context, client *teleport.Client, interval time.Duration, logger *slog.Logger) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -131,14 +319,135 @@ func runIdentityRefresh(ctx context.Context, client *teleport.Client, interval t
 			return
 		case <-ticker.C:
 			if err := client.RefreshIdentity(ctx); err != nil {
-				logger.Printf("Failed to refresh identity: %v", err)
-			} else {
-				logger.Printf("Identity refreshed successfully")
+				logger.Error("Failed to refresh identity", logging.Err(err))
+			}
+		}
+	}
+}
+
+// runReconciliation periodically queues pending requests the watcher missed
+func runReconciliation(ctx context.Context, client *teleport.Client, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := client.Reconcile(ctx); err != nil {
+				logger.Error("Reconciliation sweep failed", logging.Err(err))
+			}
+		}
+	}
+}
+
+// runWatchProbe periodically probes Teleport so a dead watch stream is told
+// apart from an idle one
+func runWatchProbe(ctx context.Context, client *teleport.Client, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := client.Probe(ctx); err != nil {
+				logger.Warn("Teleport probe failed", logging.Err(err))
+			}
+		}
+	}
+}
+
+// runJournalCheckpoints periodically checkpoints the decision journal, so
+// only the decisions of the last interval could be removed undetected
+func runJournalCheckpoints(ctx context.Context, j *journal.Journal, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Checkpoint(); err != nil {
+				logger.Error("Failed to checkpoint decision journal", logging.Err(err))
 			}
 		}
 	}
 }
 
+// runSLASweep periodically denies or escalates requests pending for too long
+func runSLASweep(ctx context.Context, client *teleport.Client, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := client.SweepStale(ctx); err != nil {
+				logger.Error("SLA sweep failed", logging.Err(err))
+			}
+		}
+	}
+}
+
+// runRuleReload periodically reloads the rules from the configuration file
+func runRuleReload(ctx context.Context, client *teleport.Client, configPath string, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloadRules(ctx, client, configPath, logger)
+		}
+	}
+}
+
+// reloadRules re-reads the configuration file and applies its rules
+func reloadRules(ctx context.Context, client *teleport.Client, configPath string, logger *slog.Logger) {
+	cfg, err := loadConfig(configPath)
+	if err == nil {
+		err = client.ReloadRules(ctx, cfg, "")
+	}
+	if err != nil {
+		logger.Error("Failed to reload rules", "rules_version", client.RulesVersion(), logging.Err(err))
+	}
+}
+
+// newReloader returns the admin API's rule reload, re-reading configPath
+func newReloader(client *teleport.Client, configPath string) server.Reloader {
+	return func(ctx context.Context, confirm string) error {
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return trace.Wrap(client.ReloadRules(ctx, cfg, confirm))
+	}
+}
+
+// newLeaderBackend creates the configured leader election backend
+func newLeaderBackend(cfg *config.Config, client *teleport.Client) leader.Backend {
+	if cfg.LeaderElection.Backend == leader.BackendFile {
+		return leader.NewFileBackend(cfg.LeaderElection.LockFile)
+	}
+	return leader.NewSemaphoreBackend(client, cfg.LeaderElection.LeaseName, cfg.LeaderElection.Identity)
+}
+
+// newShardStore creates the configured shard membership store
+func newShardStore(cfg *config.Config, client *teleport.Client) shard.Store {
+	if cfg.Sharding.Backend == shard.BackendFile {
+		return shard.NewFileStore(cfg.Sharding.Directory)
+	}
+	return shard.NewSemaphoreStore(client, cfg.Sharding.GroupName)
+}
+
 // loadConfig loads the configuration from the specified file
 func loadConfig(path string) (*config.Config, error) {
 	bytes, err := os.ReadFile(path)
@@ -158,12 +467,242 @@ func loadConfig(path string) (*config.Config, error) {
 	if cfg.Server.HealthPath == "" {
 		cfg.Server.HealthPath = "/health"
 	}
+	if cfg.Log.Level == "" {
+		cfg.Log.Level = "info"
+	}
+	if cfg.Log.Format == "" {
+		cfg.Log.Format = logging.FormatText
+	}
+	if cfg.Tracing.Endpoint == "" {
+		cfg.Tracing.Endpoint = "localhost:4317"
+	}
+	if cfg.Tracing.SampleRatio == nil {
+		sampleAll := 1.0
+		cfg.Tracing.SampleRatio = &sampleAll
+	}
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = "teleport-autoreviewer"
+	}
+	if cfg.Server.MetricsPath == "" {
+		cfg.Server.MetricsPath = "/metrics"
+	}
+	if cfg.Admin.DecisionHistory <= 0 {
+		cfg.Admin.DecisionHistory = 1000
+	}
+	if cfg.Journal.Path == "" {
+		cfg.Journal.Path = "/var/lib/teleport-autoreviewer/decisions.jsonl"
+	}
+	if cfg.Journal.MaxSizeMB == 0 {
+		cfg.Journal.MaxSizeMB = 100
+	}
+	if cfg.Journal.MaxAge == 0 {
+		cfg.Journal.MaxAge = 24 * time.Hour
+	}
+	if cfg.Journal.CheckpointInterval == 0 {
+		cfg.Journal.CheckpointInterval = 5 * time.Minute
+	}
+	if cfg.Server.LivenessTimeout == 0 {
+		cfg.Server.LivenessTimeout = 2 * time.Minute
+	}
+	if cfg.Server.IdentityExpiryThreshold == 0 {
+		cfg.Server.IdentityExpiryThreshold = 10 * time.Minute
+	}
 	if cfg.Rejection.DefaultMessage == "" {
 		cfg.Rejection.DefaultMessage = "Access request rejected due to policy violation"
 	}
 	if cfg.Teleport.IdentityRefreshInterval == 0 {
 		cfg.Teleport.IdentityRefreshInterval = time.Hour // Default to 1 hour
 	}
+	if len(cfg.Teleport.Addrs) == 0 && cfg.Teleport.Addr != "" {
+		cfg.Teleport.Addrs = []string{cfg.Teleport.Addr}
+	}
+	if cfg.Teleport.Mode == "" {
+		cfg.Teleport.Mode = teleport.ModeAuto
+	}
+	if cfg.Teleport.DialTimeout == 0 {
+		cfg.Teleport.DialTimeout = 30 * time.Second
+	}
+
+	if cfg.Watch.Mode == "" {
+		cfg.Watch.Mode = teleport.WatchModeWatch
+	}
+	if cfg.Watch.PollInterval == 0 {
+		cfg.Watch.PollInterval = 30 * time.Second
+	}
+	if cfg.Watch.RetryInterval == 0 {
+		cfg.Watch.RetryInterval = 5 * time.Second
+	}
+	if cfg.Watch.MaxFailures <= 0 {
+		cfg.Watch.MaxFailures = 3
+	}
+	if cfg.Watch.FallbackDuration == 0 {
+		cfg.Watch.FallbackDuration = 10 * time.Minute
+	}
+	if cfg.Watch.ProbeInterval == 0 {
+		cfg.Watch.ProbeInterval = time.Minute
+	}
+	if cfg.Watch.StaleThreshold == 0 {
+		cfg.Watch.StaleThreshold = 5 * time.Minute
+	}
+	if cfg.Processing.Workers <= 0 {
+		cfg.Processing.Workers = 4
+	}
+	if cfg.Processing.QueueSize <= 0 {
+		cfg.Processing.QueueSize = 1000
+	}
+	if cfg.Processing.RequestTimeout == 0 {
+		cfg.Processing.RequestTimeout = 30 * time.Second
+	}
+	if cfg.Processing.CacheTTL == 0 {
+		cfg.Processing.CacheTTL = 24 * time.Hour
+	}
+	if cfg.Processing.ReviewRate <= 0 {
+		cfg.Processing.ReviewRate = 5
+	}
+	if cfg.Processing.ReviewBurst <= 0 {
+		cfg.Processing.ReviewBurst = 10
+	}
+	if cfg.Backlog.PageSize <= 0 {
+		cfg.Backlog.PageSize = 100
+	}
+	if cfg.Processing.Backpressure == "" {
+		cfg.Processing.Backpressure = teleport.BackpressureBlock
+	}
+
+	if cfg.SLA.Interval == 0 {
+		cfg.SLA.Interval = 5 * time.Minute
+	}
+	if cfg.SLA.DefaultMessage == "" {
+		cfg.SLA.DefaultMessage = "Access request was pending for {{.Age}}, longer than the {{.MaxAge}} allowed, and was automatically denied. Please submit a new request if access is still needed"
+	}
+	if cfg.Reload.MaxDenials == 0 {
+		cfg.Reload.MaxDenials = 10
+	}
+	if cfg.Reconciliation.Interval == 0 {
+		cfg.Reconciliation.Interval = 5 * time.Minute
+	}
+	if cfg.LeaderElection.Backend == "" {
+		cfg.LeaderElection.Backend = leader.BackendTeleport
+	}
+	if cfg.LeaderElection.LeaseName == "" {
+		cfg.LeaderElection.LeaseName = "teleport-autoreviewer"
+	}
+	if cfg.LeaderElection.LeaseDuration == 0 {
+		cfg.LeaderElection.LeaseDuration = 15 * time.Second
+	}
+	if cfg.LeaderElection.RetryInterval == 0 {
+		cfg.LeaderElection.RetryInterval = 5 * time.Second
+	}
+	if cfg.LeaderElection.LockFile == "" {
+		cfg.LeaderElection.LockFile = filepath.Join(os.TempDir(), "teleport-autoreviewer.lock")
+	}
+	if cfg.LeaderElection.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to determine leader election identity")
+		}
+		cfg.LeaderElection.Identity = hostname
+	}
+
+	if cfg.Sharding.Backend == "" {
+		cfg.Sharding.Backend = shard.BackendTeleport
+	}
+	if cfg.Sharding.GroupName == "" {
+		cfg.Sharding.GroupName = "teleport-autoreviewer"
+	}
+	if cfg.Sharding.LeaseDuration == 0 {
+		cfg.Sharding.LeaseDuration = 15 * time.Second
+	}
+	if cfg.Sharding.RefreshInterval == 0 {
+		cfg.Sharding.RefreshInterval = 5 * time.Second
+	}
+	if cfg.Sharding.Directory == "" {
+		cfg.Sharding.Directory = filepath.Join(os.TempDir(), "teleport-autoreviewer-shards")
+	}
+	if cfg.Sharding.VirtualNodes <= 0 {
+		cfg.Sharding.VirtualNodes = 64
+	}
+	if cfg.Sharding.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to determine sharding identity")
+		}
+		cfg.Sharding.Identity = hostname
+	}
+
+	// Validate connection options
+	if len(cfg.Teleport.Addrs) == 0 {
+		return nil, trace.BadParameter("teleport.addr or teleport.addrs must be set")
+	}
+	switch cfg.Teleport.Mode {
+	case teleport.ModeAuto, teleport.ModeAuth, teleport.ModeProxy:
+	default:
+		return nil, trace.BadParameter("unknown teleport.mode %q, expected %q, %q or %q",
+			cfg.Teleport.Mode, teleport.ModeAuto, teleport.ModeAuth, teleport.ModeProxy)
+	}
+	if cfg.Teleport.TLSRouting && cfg.Teleport.Mode != teleport.ModeProxy {
+		return nil, trace.BadParameter("teleport.tls_routing requires teleport.mode %q", teleport.ModeProxy)
+	}
+	switch cfg.Watch.Mode {
+	case teleport.WatchModeWatch, teleport.WatchModePoll, teleport.WatchModeAuto:
+	default:
+		return nil, trace.BadParameter("unknown watch.mode %q, expected %q, %q or %q",
+			cfg.Watch.Mode, teleport.WatchModeWatch, teleport.WatchModePoll, teleport.WatchModeAuto)
+	}
+	if cfg.LeaderElection.Backend != leader.BackendTeleport && cfg.LeaderElection.Backend != leader.BackendFile {
+		return nil, trace.BadParameter("unknown leader_election.backend %q, expected %q or %q",
+			cfg.LeaderElection.Backend, leader.BackendTeleport, leader.BackendFile)
+	}
+	if cfg.Sharding.Backend != shard.BackendTeleport && cfg.Sharding.Backend != shard.BackendFile {
+		return nil, trace.BadParameter("unknown sharding.backend %q, expected %q or %q",
+			cfg.Sharding.Backend, shard.BackendTeleport, shard.BackendFile)
+	}
+	if cfg.Sharding.Enabled && cfg.LeaderElection.Enabled {
+		return nil, trace.BadParameter("sharding and leader_election cannot both be enabled")
+	}
+	if cfg.Processing.Backpressure != teleport.BackpressureBlock && cfg.Processing.Backpressure != teleport.BackpressureDrop {
+		return nil, trace.BadParameter("unknown processing.backpressure %q, expected %q or %q",
+			cfg.Processing.Backpressure, teleport.BackpressureBlock, teleport.BackpressureDrop)
+	}
+	if cfg.Processing.CacheTTL < 0 {
+		return nil, trace.BadParameter("processing.cache_ttl must not be negative, got %v", cfg.Processing.CacheTTL)
+	}
+	if ratio := *cfg.Tracing.SampleRatio; ratio < 0 || ratio > 1 {
+		return nil, trace.BadParameter("tracing.sample_ratio must be between 0 and 1, got %v", ratio)
+	}
+	if cfg.Journal.MaxSizeMB < 0 || cfg.Journal.MaxAge < 0 || cfg.Journal.MaxBackups < 0 || cfg.Journal.CheckpointInterval < 0 {
+		return nil, trace.BadParameter("journal.max_size_mb, journal.max_age, journal.max_backups and journal.checkpoint_interval must not be negative")
+	}
+	if cfg.Journal.Enabled && cfg.Journal.SigningKeyFile == "" {
+		return nil, trace.BadParameter("journal.enabled requires journal.signing_key_file, unsigned checkpoints can't show the journal was not rewritten")
+	}
+	if err := validateTLS("server.tls", cfg.Server.TLS); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := validateTLS("admin.tls", cfg.Admin.TLS); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if cfg.Admin.Enabled && cfg.Admin.ListenAddress == "" && cfg.Admin.TokenFile == "" {
+		return nil, trace.BadParameter("admin.enabled without admin.listen_address serves the admin API on the health port, which requires admin.token_file")
+	}
+	if cfg.Admin.Enabled && cfg.Admin.TokenFile == "" && cfg.Admin.TLS.ClientCAFile == "" {
+		return nil, trace.BadParameter("admin.enabled requires admin.token_file or admin.tls.client_ca_file, the admin API can change state")
+	}
+	if cfg.Admin.ListenAddress == "" && cfg.Admin.TLS != (config.TLS{}) {
+		return nil, trace.BadParameter("admin.tls requires admin.listen_address, the admin API otherwise shares the health server and its server.tls")
+	}
 
 	return &cfg, nil
 }
+
+// validateTLS checks that a TLS section is either empty or has both a
+// certificate and a key
+func validateTLS(section string, tls config.TLS) error {
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		return trace.BadParameter("%s.cert_file and %s.key_file must be set together", section, section)
+	}
+	if tls.ClientCAFile != "" && tls.CertFile == "" {
+		return trace.BadParameter("%s.client_ca_file requires %s.cert_file and %s.key_file", section, section, section)
+	}
+	return nil
+}
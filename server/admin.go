@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/status"
+	"teleport-autoreviewer/teleport"
+)
+
+// Admin API defaults
+const (
+	// defaultDecisionLimit is how many decisions are listed when no limit is given
+	defaultDecisionLimit = 100
+	// maxEvaluateBody caps the size of an evaluate request body
+	maxEvaluateBody = 1 << 20
+)
+
+// Reloader reloads the rules from the configuration file. If confirm is the
+// version of the new rules, they are applied even if they deny more pending
+// requests than reload.max_denials allows.
+type Reloader func(ctx context.Context, confirm string) error
+
+// AdminAPI serves the operator endpoints for inspecting decisions and rules
+// and triggering reloads, reconciliation and ad-hoc evaluations, along with a
+// dashboard page for reviewers
+type AdminAPI struct {
+	client   *teleport.Client
+	registry *status.Registry
+	reload   Reloader
+	token    string
+	logger   *slog.Logger
+}
+
+// evaluateRequest is the body of an ad-hoc evaluation
+type evaluateRequest struct {
+	User   string   `json:"user"`
+	Roles  []string `json:"roles"`
+	Reason string   `json:"reason"`
+}
+
+// NewAdminAPI creates the admin API. When token is set, mutating endpoints,
+// or all of them when sharing the health listener, require it as a bearer
+// token.
+func NewAdminAPI(client *teleport.Client, registry *status.Registry, reload Reloader, token string, logger *slog.Logger) *AdminAPI {
+	return &AdminAPI{
+		client:   client,
+		registry: registry,
+		reload:   reload,
+		token:    token,
+		logger:   logger,
+	}
+}
+
+// Register adds the admin endpoints to mux. The POST endpoints require the
+// bearer token if one is configured, and with authenticateAll so do the ones
+// that only read.
+func (a *AdminAPI) Register(mux *http.ServeMux, authenticateAll bool) {
+	read := func(next http.HandlerFunc) http.HandlerFunc {
+		if !authenticateAll {
+			return next
+		}
+		return a.authenticated(next)
+	}
+	mux.HandleFunc("GET /admin/dashboard", read(a.dashboard))
+	mux.HandleFunc("GET /admin/decisions", read(a.listDecisions))
+	mux.HandleFunc("GET /admin/decisions/stream", read(a.streamDecisions))
+	mux.HandleFunc("GET /admin/decisions/{id}", read(a.getDecision))
+	mux.HandleFunc("GET /admin/rules", read(a.getRules))
+	mux.HandleFunc("POST /admin/reload", a.authenticated(a.triggerReload))
+	mux.HandleFunc("POST /admin/reconcile", a.authenticated(a.triggerReconcile))
+	mux.HandleFunc("POST /admin/evaluate", a.authenticated(a.evaluate))
+}
+
+// authenticated guards an endpoint with the bearer token, if one is configured
+func (a *AdminAPI) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	if a.token == "" {
+		return next
+	}
+	return requireToken(a.token, next)
+}
+
+// listDecisions lists recent decisions, filtered by the request_id, user,
+// rule, outcome and since query parameters
+func (a *AdminAPI) listDecisions(w http.ResponseWriter, r *http.Request) {
+	filter, err := decisionFilter(r)
+	if err != nil {
+		trace.WriteError(w, err)
+		return
+	}
+	decisions := a.client.Decisions(filter)
+	if decisions == nil {
+		decisions = []teleport.Decision{}
+	}
+	a.writeJSON(w, map[string]any{"decisions": decisions})
+}
+
+// decisionFilter parses the decision filter from the query parameters
+func decisionFilter(r *http.Request) (teleport.DecisionFilter, error) {
+	query := r.URL.Query()
+	filter := teleport.DecisionFilter{
+		RequestID: query.Get("request_id"),
+		User:      query.Get("user"),
+		Rule:      query.Get("rule"),
+		Outcome:   teleport.Outcome(query.Get("outcome")),
+		Limit:     defaultDecisionLimit,
+	}
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, trace.BadParameter("invalid since %q, expected an RFC 3339 time", since)
+		}
+		filter.Since = t
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return filter, trace.BadParameter("invalid limit %q, expected a positive number", limit)
+		}
+		filter.Limit = n
+	}
+	return filter, nil
+}
+
+// getDecision returns a single decision by ID
+func (a *AdminAPI) getDecision(w http.ResponseWriter, r *http.Request) {
+	decision, err := a.client.Decision(r.PathValue("id"))
+	if err != nil {
+		trace.WriteError(w, err)
+		return
+	}
+	a.writeJSON(w, decision)
+}
+
+// getRules returns the rules in effect and their version
+func (a *AdminAPI) getRules(w http.ResponseWriter, r *http.Request) {
+	a.writeJSON(w, a.client.Rules())
+}
+
+// triggerReload reloads the rules from the configuration file. The confirm
+// query parameter applies rules of that version even if they would deny more
+// than reload.max_denials pending requests.
+func (a *AdminAPI) triggerReload(w http.ResponseWriter, r *http.Request) {
+	confirm := r.URL.Query().Get("confirm")
+	previous := a.client.RulesVersion()
+	a.logger.Info("Admin API triggered rule reload", "remote_addr", r.RemoteAddr, "confirm", confirm)
+
+	if err := a.reload(r.Context(), confirm); err != nil {
+		a.logger.Error("Failed to reload rules", "rules_version", previous, logging.Err(err))
+		trace.WriteError(w, err)
+		return
+	}
+	version := a.client.RulesVersion()
+	a.writeJSON(w, map[string]any{
+		"previous_version": previous,
+		"rules_version":    version,
+		"changed":          version != previous,
+	})
+}
+
+// triggerReconcile runs a reconciliation sweep
+func (a *AdminAPI) triggerReconcile(w http.ResponseWriter, r *http.Request) {
+	a.logger.Info("Admin API triggered reconciliation sweep", "remote_addr", r.RemoteAddr)
+
+	drift, err := a.client.Reconcile(r.Context())
+	if err != nil {
+		a.logger.Error("Reconciliation sweep failed", logging.Err(err))
+		trace.WriteError(w, err)
+		return
+	}
+	a.writeJSON(w, map[string]any{"drift": drift})
+}
+
+// evaluate evaluates a hypothetical request against the rules in effect
+func (a *AdminAPI) evaluate(w http.ResponseWriter, r *http.Request) {
+	var req evaluateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEvaluateBody)).Decode(&req); err != nil {
+		trace.WriteError(w, trace.BadParameter("invalid evaluate request: %v", err))
+		return
+	}
+
+	eval, err := a.client.Evaluate(r.Context(), req.User, req.Roles, req.Reason)
+	if err != nil {
+		trace.WriteError(w, err)
+		return
+	}
+	a.writeJSON(w, eval)
+}
+
+// writeJSON writes v as a JSON response
+func (a *AdminAPI) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		a.logger.Error("Failed to encode admin API response", logging.Err(err))
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/status"
+	"teleport-autoreviewer/teleport"
+)
+
+// defaultDashboardDecisions is how many decisions the dashboard lists when no
+// limit is given
+const defaultDashboardDecisions = 50
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+// dashboardTemplate renders the dashboard page
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"ago": func(t any) string {
+		switch t := t.(type) {
+		case time.Time:
+			return time.Since(t).Round(time.Second).String()
+		case *time.Time:
+			if t != nil {
+				return time.Since(*t).Round(time.Second).String()
+			}
+		}
+		return ""
+	},
+	"until": func(t time.Time) string {
+		return time.Until(t).Round(time.Second).String()
+	},
+	"join": strings.Join,
+}).Parse(dashboardHTML))
+
+// dashboardData is the data the dashboard is rendered from
+type dashboardData struct {
+	Generated time.Time
+	Report    status.Report
+	Health    *teleport.HealthStatus
+	Rules     teleport.RuleSet
+	Hits      map[string]uint64
+	Decisions []teleport.Decision
+}
+
+// dashboard renders an HTML page of the service's state, its rules and the
+// most recent decisions, limited by the limit query parameter
+func (a *AdminAPI) dashboard(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDashboardDecisions
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			trace.WriteError(w, trace.BadParameter("invalid limit %q, expected a positive number", l))
+			return
+		}
+		limit = n
+	}
+
+	data := dashboardData{
+		Generated: time.Now(),
+		Report:    a.registry.Report(),
+		Health:    a.client.GetHealthStatus(),
+		Rules:     a.client.Rules(),
+		Hits:      a.client.RuleHits(),
+		Decisions: a.client.Decisions(teleport.DecisionFilter{Limit: limit}),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		a.logger.Error("Failed to render dashboard", logging.Err(err))
+	}
+}
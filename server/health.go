@@ -2,119 +2,154 @@ package server
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
-	"teleport-autoreviewer/teleport"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/config"
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/status"
 )
 
-// HealthStatus represents the overall health status
-type HealthStatus struct {
-	Status               string    `json:"status"`
-	TeleportConnected    bool      `json:"teleport_connected"`
-	IdentityValid        bool      `json:"identity_valid"`
-	LastRequestProcessed time.Time `json:"last_request_processed"`
-	LastIdentityRefresh  time.Time `json:"last_identity_refresh"`
-	Uptime               string    `json:"uptime"`
-}
+// Probe endpoints served next to the health endpoint
+const (
+	LivenessPath  = "/livez"
+	ReadinessPath = "/readyz"
+)
 
-// HealthServer provides HTTP health check endpoints
+// HealthServer serves the status registry and metrics over HTTP, and the
+// admin API either on the same listener or on its own
 type HealthServer struct {
-	port      int
-	path      string
-	server    *http.Server
-	logger    *log.Logger
-	client    *teleport.Client
-	startTime time.Time
-	mu        sync.RWMutex
+	listeners []*listener
+	logger    *slog.Logger
 }
 
-// NewHealthServer creates a new health check server
-func NewHealthServer(port int, path string, client *teleport.Client, logger *log.Logger) *HealthServer {
-	return &HealthServer{
-		port:      port,
-		path:      path,
-		client:    client,
-		logger:    logger,
-		startTime: time.Now(),
-	}
+// listener is a single HTTP server with its optional TLS configuration
+type listener struct {
+	name   string
+	server *http.Server
+	tls    *tls.Config
 }
 
-// Start starts the health check HTTP server
-func (h *HealthServer) Start(ctx context.Context) error {
+// NewHealthServer creates a new health check server. The admin API is served
+// too unless admin is nil, on its own listener if admin.listen_address is set.
+// On the health listener every admin endpoint requires the bearer token.
+func NewHealthServer(cfg *config.Config, registry *status.Registry, admin *AdminAPI, logger *slog.Logger) (*HealthServer, error) {
 	mux := http.NewServeMux()
-	mux.HandleFunc(h.path, h.healthHandler)
-
-	h.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", h.port),
-		Handler: mux,
+	mux.Handle(cfg.Server.HealthPath, registry.HealthHandler(logger))
+	mux.Handle(LivenessPath, registry.ProbeHandler(logger, status.KindLiveness))
+	mux.Handle(ReadinessPath, registry.ProbeHandler(logger, status.KindReadiness))
+	mux.Handle(cfg.Server.MetricsPath, metrics.Handler())
+
+	// Kubelet probes carry no client certificate, so the probe endpoints are
+	// exempt from server.tls.client_ca_file
+	healthTLS, err := newTLSConfig(cfg.Server.TLS, true)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to configure server.tls")
 	}
-
-	h.logger.Printf("Health check server starting on port %d, path %s", h.port, h.path)
-
-	go func() {
-		<-ctx.Done()
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := h.server.Shutdown(shutdownCtx); err != nil {
-			h.logger.Printf("Health server shutdown error: %v", err)
-		}
-	}()
-
-	if err := h.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("health server failed: %w", err)
+	var handler http.Handler = mux
+	if healthTLS != nil && healthTLS.ClientCAs != nil {
+		handler = requireClientCert(mux, LivenessPath, ReadinessPath)
+	}
+	h := &HealthServer{logger: logger}
+	h.listeners = append(h.listeners, &listener{
+		name:   "health",
+		server: &http.Server{Addr: net.JoinHostPort(cfg.Server.BindAddress, strconv.Itoa(cfg.Server.HealthPort)), Handler: handler},
+		tls:    healthTLS,
+	})
+
+	if admin == nil {
+		return h, nil
+	}
+	if cfg.Admin.ListenAddress == "" {
+		admin.Register(mux, true)
+		return h, nil
 	}
 
-	return nil
+	adminMux := http.NewServeMux()
+	admin.Register(adminMux, false)
+	adminTLS, err := newTLSConfig(cfg.Admin.TLS, false)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to configure admin.tls")
+	}
+	h.listeners = append(h.listeners, &listener{
+		name:   "admin",
+		server: &http.Server{Addr: cfg.Admin.ListenAddress, Handler: adminMux},
+		tls:    adminTLS,
+	})
+	return h, nil
 }
 
-// Stop stops the health check server
-func (h *HealthServer) Stop() error {
-	if h.server != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		return h.server.Shutdown(ctx)
+// Start serves every listener until ctx is cancelled or one of them fails
+func (h *HealthServer) Start(ctx context.Context) error {
+	errCh := make(chan error, len(h.listeners))
+	var wg sync.WaitGroup
+	for _, l := range h.listeners {
+		// Requests end with ctx, so long-lived decision streams don't hold up shutdown
+		l.server.BaseContext = func(net.Listener) context.Context { return ctx }
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := h.serve(l); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errCh:
 	}
-	return nil
-}
 
-// healthHandler handles health check requests
-func (h *HealthServer) healthHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, l := range h.listeners {
+		if shutdownErr := l.server.Shutdown(shutdownCtx); shutdownErr != nil {
+			h.logger.Error("Health server shutdown failed", "listener", l.name, logging.Err(shutdownErr))
+		}
 	}
+	wg.Wait()
+	return err
+}
 
-	h.mu.RLock()
-	teleportHealth := h.client.GetHealthStatus()
-	lastRequestTime := h.client.GetLastRequestTime()
-	h.mu.RUnlock()
-
-	status := HealthStatus{
-		TeleportConnected:    teleportHealth.TeleportConnected,
-		IdentityValid:        teleportHealth.IdentityValid,
-		LastRequestProcessed: lastRequestTime,
-		LastIdentityRefresh:  teleportHealth.LastRefresh,
-		Uptime:               time.Since(h.startTime).String(),
+// serve runs a single listener until it is shut down
+func (h *HealthServer) serve(l *listener) error {
+	scheme := "http"
+	if l.tls != nil {
+		scheme = "https"
 	}
+	h.logger.Info("Health check server starting", "listener", l.name, "address", l.server.Addr,
+		"scheme", scheme, "client_certificates", l.tls != nil && l.tls.ClientCAs != nil)
 
-	// Determine overall status
-	if status.TeleportConnected && status.IdentityValid {
-		status.Status = "healthy"
-		w.WriteHeader(http.StatusOK)
+	var err error
+	if l.tls != nil {
+		l.server.TLSConfig = l.tls
+		err = l.server.ListenAndServeTLS("", "")
 	} else {
-		status.Status = "unhealthy"
-		w.WriteHeader(http.StatusServiceUnavailable)
+		err = l.server.ListenAndServe()
 	}
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("%s server failed: %w", l.name, err)
+	}
+	return nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(status); err != nil {
-		h.logger.Printf("Failed to encode health status: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+// Stop stops the health check server
+func (h *HealthServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var errs []error
+	for _, l := range h.listeners {
+		errs = append(errs, l.server.Shutdown(ctx))
 	}
+	return trace.NewAggregate(errs...)
 }
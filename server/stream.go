@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/teleport"
+)
+
+// streamKeepAlive is how often an idle decision stream sends a comment so
+// proxies don't close the connection
+const streamKeepAlive = 15 * time.Second
+
+// streamDecisions pushes every new decision as a Server-Sent Event, filtered
+// by the request_id, user, rule and outcome query parameters. A "dropped"
+// event reports decisions skipped because the client fell behind.
+func (a *AdminAPI) streamDecisions(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		trace.WriteError(w, trace.NotImplemented("streaming is not supported by this connection"))
+		return
+	}
+	query := r.URL.Query()
+	filter := teleport.DecisionFilter{
+		RequestID: query.Get("request_id"),
+		User:      query.Get("user"),
+		Rule:      query.Get("rule"),
+		Outcome:   teleport.Outcome(query.Get("outcome")),
+	}
+
+	sub := a.client.SubscribeDecisions(r.Context())
+	a.logger.Info("Decision stream opened", "remote_addr", r.RemoteAddr, logging.KeyUser, filter.User, logging.KeyRule, filter.Rule)
+	defer a.logger.Info("Decision stream closed", "remote_addr", r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": streaming decisions\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case decision, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if dropped := sub.Dropped(); dropped > 0 {
+				fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", dropped)
+			}
+			if !filter.Matches(&decision) {
+				continue
+			}
+			data, err := json.Marshal(decision)
+			if err != nil {
+				a.logger.Error("Failed to encode decision", logging.KeyDecisionID, decision.ID, logging.Err(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: decision\ndata: %s\n\n", decision.ID, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/config"
+)
+
+// newTLSConfig builds the TLS configuration of a listener, or returns nil
+// when no certificate is configured and the listener serves plain HTTP. With
+// a client CA, clients must present a certificate signed by it, unless
+// optionalClientCert is set, in which case the handler must check for one
+// with requireClientCert.
+func newTLSConfig(cfg config.TLS, optionalClientCert bool) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to load TLS certificate")
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to read client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, trace.BadParameter("no certificates found in client CA file %q", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if optionalClientCert {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	return tlsConfig, nil
+}
+
+// requireClientCert rejects requests without a verified client certificate,
+// except for the exempt paths
+func requireClientCert(next http.Handler, exempt ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			if !slices.Contains(exempt, r.URL.Path) {
+				http.Error(w, "client certificate required", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoadToken reads a bearer token from path. An empty path returns an empty
+// token, which disables token authentication.
+func LoadToken(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", trace.Wrap(err, "failed to read admin token file")
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", trace.BadParameter("admin token file %q is empty", path)
+	}
+	return token, nil
+}
+
+// requireToken rejects requests that don't carry the bearer token
+func requireToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="teleport-autoreviewer admin"`)
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,111 @@
+package teleport
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+)
+
+// RuleSet is the rule configuration in effect
+type RuleSet struct {
+	Version           string        `json:"version"`
+	DefaultMessage    string        `json:"default_message"`
+	Rules             []RuleInfo    `json:"rules"`
+	SLADefaultMessage string        `json:"sla_default_message,omitempty"`
+	SLARules          []SLARuleInfo `json:"sla_rules,omitempty"`
+}
+
+// RuleInfo describes a rejection rule
+type RuleInfo struct {
+	Name        string `json:"name"`
+	RolesRegex  string `json:"roles_regex,omitempty"`
+	ReasonRegex string `json:"reason_regex,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// SLARuleInfo describes an SLA rule
+type SLARuleInfo struct {
+	Name       string `json:"name"`
+	RolesRegex string `json:"roles_regex,omitempty"`
+	MaxAge     string `json:"max_age"`
+	Action     string `json:"action"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Rules returns the rules in effect and their version
+func (c *Client) Rules() RuleSet {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	set := RuleSet{
+		Version:           c.rulesVersion,
+		DefaultMessage:    c.config.Rejection.DefaultMessage,
+		Rules:             make([]RuleInfo, 0, len(c.config.Rejection.Rules)),
+		SLADefaultMessage: c.config.SLA.DefaultMessage,
+	}
+	for _, rule := range c.config.Rejection.Rules {
+		set.Rules = append(set.Rules, RuleInfo{
+			Name:        rule.Name,
+			RolesRegex:  rule.RolesRegex,
+			ReasonRegex: rule.ReasonRegex,
+			Message:     rule.Message,
+		})
+	}
+	for _, rule := range c.config.SLA.Rules {
+		set.SLARules = append(set.SLARules, SLARuleInfo{
+			Name:       rule.Name,
+			RolesRegex: rule.RolesRegex,
+			MaxAge:     rule.MaxAge.String(),
+			Action:     rule.Action,
+			Message:    rule.Message,
+		})
+	}
+	return set
+}
+
+// Evaluation is the result of evaluating a hypothetical request
+type Evaluation struct {
+	Outcome      Outcome     `json:"outcome"`
+	Rule         string      `json:"rule,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	Checks       []RuleCheck `json:"checks"`
+	RulesVersion string      `json:"rules_version"`
+}
+
+// Evaluate checks a hypothetical request by user for roles against the
+// rejection rules in effect. Nothing is reviewed: the outcome is what the
+// service would decide if such a request were created.
+func (c *Client) Evaluate(ctx context.Context, user string, roles []string, reason string) (*Evaluation, error) {
+	if user == "" {
+		return nil, trace.BadParameter("missing user")
+	}
+	if len(roles) == 0 {
+		return nil, trace.BadParameter("missing roles")
+	}
+
+	req, err := types.NewAccessRequest("evaluate-"+newDecisionID(), user, roles...)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	req.SetRequestReason(reason)
+
+	ctx, span := tracer.Start(ctx, "autoreviewer.evaluate")
+	defer span.End()
+
+	log := c.logger.With(logging.KeyUser, user, logging.KeySource, "evaluate")
+	rule, checks := c.shouldReject(ctx, req, log)
+	eval := &Evaluation{
+		Outcome:      OutcomeAllowed,
+		Checks:       checks,
+		RulesVersion: c.RulesVersion(),
+	}
+	if rule != nil {
+		eval.Outcome = OutcomeDenied
+		eval.Rule = rule.Name
+		eval.Message = c.ruleMessage(rule)
+	}
+	return eval, nil
+}
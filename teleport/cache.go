@@ -0,0 +1,100 @@
+package teleport
+
+import (
+	"sync"
+	"time"
+)
+
+// processedCache remembers which request versions have already been decided
+// so the backlog scan, watch events and reconnections never review the same
+// version twice. Entries are keyed by request ID and resource revision.
+type processedCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]map[string]time.Time
+	lastPrune time.Time
+}
+
+// newProcessedCache creates a cache whose entries expire after ttl
+func newProcessedCache(ttl time.Duration) *processedCache {
+	return &processedCache{
+		ttl:       ttl,
+		entries:   make(map[string]map[string]time.Time),
+		lastPrune: time.Now(),
+	}
+}
+
+// claim marks the request version as decided. It returns false if the version
+// was already claimed and has not expired.
+func (p *processedCache) claim(id, revision string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(p.lastPrune) > p.ttl/2 {
+		p.prune(now)
+	}
+
+	revisions, ok := p.entries[id]
+	if !ok {
+		revisions = make(map[string]time.Time)
+		p.entries[id] = revisions
+	}
+	if expires, ok := revisions[revision]; ok && now.Before(expires) {
+		return false
+	}
+	revisions[revision] = now.Add(p.ttl)
+	return true
+}
+
+// has reports whether the request version has been claimed and not expired
+func (p *processedCache) has(id, revision string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expires, ok := p.entries[id][revision]
+	return ok && time.Now().Before(expires)
+}
+
+// release forgets a claimed version so it can be processed again, used when
+// a decision could not be applied
+func (p *processedCache) release(id, revision string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if revisions, ok := p.entries[id]; ok {
+		delete(revisions, revision)
+		if len(revisions) == 0 {
+			delete(p.entries, id)
+		}
+	}
+}
+
+// evict forgets every version of a request, used when it is deleted
+func (p *processedCache) evict(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, id)
+}
+
+// size returns the number of requests in the cache
+func (p *processedCache) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+// prune drops expired entries. Must be called with the lock held.
+func (p *processedCache) prune(now time.Time) {
+	for id, revisions := range p.entries {
+		for revision, expires := range revisions {
+			if !now.Before(expires) {
+				delete(revisions, revision)
+			}
+		}
+		if len(revisions) == 0 {
+			delete(p.entries, id)
+		}
+	}
+	p.lastPrune = now
+}
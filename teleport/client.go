@@ -2,7 +2,11 @@ package teleport
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"maps"
 	"regexp"
 	"sync"
 	"time"
@@ -10,19 +14,78 @@ import (
 	"github.com/gravitational/teleport/api/client"
 	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/trace"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 
 	"teleport-autoreviewer/config"
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/redact"
+	"teleport-autoreviewer/internal/status"
+	"teleport-autoreviewer/internal/tracing"
 )
 
 // Client is a Teleport client with auto-rejection capabilities
 type Client struct {
 	*client.Client
 	config          *config.Config
-	logger          *log.Logger
+	logger          *slog.Logger
 	mu              sync.RWMutex
 	compiledRules   []*CompiledRule
 	healthStatus    *HealthStatus
 	lastRequestTime time.Time
+	queue           *workQueue
+	processed       *processedCache
+	outcomes        map[Outcome]uint64
+	ruleHits        map[string]uint64
+	role            string
+	sharder         ShardOwner
+	reconcileDrift  uint64
+	lastReconcile   time.Time
+	watchMode       string
+	watchFailures   int
+	reviewLimiter   *rate.Limiter
+	slaRules        []*SLARule
+	escalated       map[string]bool
+	rulesVersion    string
+	lastReload      time.Time
+	lastReloadError string
+	heldRules       *heldRules
+	registry        *status.Registry
+	redactor        *redact.Redactor
+	history         *decisionLog
+	journal         DecisionJournal
+	watchReady      bool
+	watchBeat       time.Time
+
+	// Whether a scan of pending requests is running, and whether another
+	// was requested while it ran
+	scanning    bool
+	rescanAgain bool
+
+	// Last sign of life from the watch stream and last successful probe,
+	// to tell a quiet cluster from a dead stream
+	lastWatchActivity time.Time
+	lastProbe         time.Time
+}
+
+// Leader election roles reported in health
+const (
+	// RoleStandalone means leader election is disabled
+	RoleStandalone = "standalone"
+	// RoleLeader means this replica holds the leadership lease and reviews requests
+	RoleLeader = "leader"
+	// RoleFollower means another replica is reviewing requests
+	RoleFollower = "follower"
+	// RoleShard means this replica reviews its share of requests alongside its peers
+	RoleShard = "shard"
+)
+
+// ShardOwner decides which requests this replica reviews when sharding is enabled
+type ShardOwner interface {
+	Owns(id string) bool
+	Members() []string
 }
 
 // CompiledRule contains a compiled regex rule for efficient matching
@@ -33,25 +96,47 @@ type CompiledRule struct {
 	Message     string
 }
 
-// HealthStatus tracks the health of the teleport client
+// HealthStatus tracks the health of the teleport client. It is reported as
+// the "teleport" section of the detailed health report.
 type HealthStatus struct {
-	TeleportConnected bool
-	IdentityValid     bool
-	LastRefresh       time.Time
+	TeleportConnected    bool               `json:"teleport_connected"`
+	IdentityValid        bool               `json:"identity_valid"`
+	LastRefresh          time.Time          `json:"last_identity_refresh"`
+	IdentityExpiry       time.Time          `json:"identity_expiry"`
+	MissingPermissions   []string           `json:"missing_permissions,omitempty"`
+	LastRequestProcessed time.Time          `json:"last_request_processed"`
+	QueueDepth           int                `json:"queue_depth"`
+	QueueCapacity        int                `json:"queue_capacity"`
+	QueueDropped         uint64             `json:"queue_dropped"`
+	Outcomes             map[Outcome]uint64 `json:"decisions"`
+	Role                 string             `json:"role"`
+	ShardMembers         []string           `json:"shard_members,omitempty"`
+	ReconcileDrift       uint64             `json:"reconcile_drift"`
+	LastReconcile        time.Time          `json:"last_reconcile"`
+	WatchMode            string             `json:"watch_mode"`
+	WatchFailures        int                `json:"watch_failures"`
+	RulesVersion         string             `json:"rules_version"`
+	LastReload           time.Time          `json:"last_reload,omitempty"`
+	LastReloadError      string             `json:"last_reload_error,omitempty"`
+	LastWatchActivity    time.Time          `json:"last_watch_activity"`
+	LastProbe            time.Time          `json:"last_probe,omitempty"`
+	WatchStale           bool               `json:"watch_stale"`
 }
 
 // New creates a new Teleport client
-func New(ctx context.Context, cfg *config.Config, logger *log.Logger) (*Client, error) {
-	creds := client.LoadIdentityFile(cfg.Teleport.Identity)
+func New(ctx context.Context, cfg *config.Config, registry *status.Registry, logger *slog.Logger) (*Client, error) {
+	redactor, err := redact.New(cfg)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
 
-	c, err := client.New(ctx, client.Config{
-		Addrs:       []string{cfg.Teleport.Addr},
-		Credentials: []client.Credentials{creds},
-	})
+	c, pong, err := dial(ctx, cfg)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
+	logger.Info("Connected to Teleport cluster", "cluster", pong.GetClusterName(), "server_version", pong.GetServerVersion())
+
 	client := &Client{
 		Client: c,
 		config: cfg,
@@ -61,12 +146,53 @@ func New(ctx context.Context, cfg *config.Config, logger *log.Logger) (*Client,
 			IdentityValid:     true,
 			LastRefresh:       time.Now(),
 		},
+		queue: newWorkQueue(
+			cfg.Processing.Workers,
+			cfg.Processing.QueueSize,
+			cfg.Processing.RequestTimeout,
+			cfg.Processing.Backpressure,
+			logger,
+		),
+		processed:         newProcessedCache(cfg.Processing.CacheTTL),
+		outcomes:          make(map[Outcome]uint64),
+		ruleHits:          make(map[string]uint64),
+		role:              RoleStandalone,
+		escalated:         make(map[string]bool),
+		watchBeat:         time.Now(),
+		lastWatchActivity: time.Now(),
+		registry:          registry,
+		redactor:          redactor,
+		history:           newDecisionLog(cfg.Admin.DecisionHistory),
+		reviewLimiter: rate.NewLimiter(
+			rate.Limit(cfg.Processing.ReviewRate),
+			cfg.Processing.ReviewBurst,
+		),
 	}
 
-	// Compile regex rules
-	if err := client.compileRules(); err != nil {
+	// Replicas start as followers until they win an election
+	if cfg.LeaderElection.Enabled {
+		client.role = RoleFollower
+	}
+
+	// Compile regex rules and pending-request SLA policies
+	if client.compiledRules, err = compileRules(cfg.Rejection.Rules); err != nil {
 		return nil, trace.Wrap(err, "failed to compile rejection rules")
 	}
+	if client.slaRules, err = compileSLARules(cfg); err != nil {
+		return nil, trace.Wrap(err, "failed to compile SLA rules")
+	}
+	client.rulesVersion = rulesVersion(cfg)
+	metrics.SetRulesVersion(client.rulesVersion)
+	logger.Info("Compiled rules",
+		"rejection_rules", len(client.compiledRules), "sla_rules", len(client.slaRules), "rules_version", client.rulesVersion)
+
+	client.recordIdentityExpiry()
+	client.registerChecks()
+
+	// Verify the bot can actually do its job before watching
+	if err := client.CheckPermissions(ctx); err != nil {
+		logger.Warn("Permission self-check failed", logging.Err(err))
+	}
 
 	return client, nil
 }
@@ -76,9 +202,28 @@ func (c *Client) GetHealthStatus() *HealthStatus {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return &HealthStatus{
-		TeleportConnected: c.healthStatus.TeleportConnected,
-		IdentityValid:     c.healthStatus.IdentityValid,
-		LastRefresh:       c.healthStatus.LastRefresh,
+		TeleportConnected:    c.healthStatus.TeleportConnected,
+		IdentityValid:        c.healthStatus.IdentityValid,
+		LastRefresh:          c.healthStatus.LastRefresh,
+		IdentityExpiry:       c.healthStatus.IdentityExpiry,
+		MissingPermissions:   append([]string(nil), c.healthStatus.MissingPermissions...),
+		LastRequestProcessed: c.lastRequestTime,
+		QueueDepth:           c.queue.depth(),
+		QueueCapacity:        c.queue.capacity(),
+		QueueDropped:         c.queue.dropped.Load(),
+		Outcomes:             maps.Clone(c.outcomes),
+		Role:                 c.role,
+		ShardMembers:         c.shardMembers(),
+		ReconcileDrift:       c.reconcileDrift,
+		LastReconcile:        c.lastReconcile,
+		WatchMode:            c.watchMode,
+		WatchFailures:        c.watchFailures,
+		RulesVersion:         c.rulesVersion,
+		LastReload:           c.lastReload,
+		LastReloadError:      c.lastReloadError,
+		LastWatchActivity:    c.lastWatchActivity,
+		LastProbe:            c.lastProbe,
+		WatchStale:           c.watchStale(),
 	}
 }
 
@@ -89,17 +234,102 @@ func (c *Client) GetLastRequestTime() time.Time {
 	return c.lastRequestTime
 }
 
+// Role returns the replica's leader election role
+func (c *Client) Role() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.role
+}
+
+// SetLeader records a leadership change. A new leader rescans pending
+// requests, since the previous leader may not have decided all of them.
+func (c *Client) SetLeader(ctx context.Context, leader bool) {
+	c.mu.Lock()
+	if leader {
+		c.role = RoleLeader
+	} else {
+		c.role = RoleFollower
+	}
+	c.mu.Unlock()
+
+	if leader {
+		c.rescan(ctx, "taking leadership")
+	}
+}
+
+// SetSharder makes this replica review only the requests it owns
+func (c *Client) SetSharder(sharder ShardOwner) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sharder = sharder
+	c.role = RoleShard
+}
+
+// Rebalance rescans pending requests after shard membership changed, so the
+// requests of a departed peer are picked up by their new owner
+func (c *Client) Rebalance(ctx context.Context) {
+	c.rescan(ctx, "shard rebalance")
+}
+
+// owns reports whether this replica is responsible for reviewing the request
+func (c *Client) owns(id string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch {
+	case c.role == RoleFollower:
+		return false
+	case c.sharder != nil:
+		return c.sharder.Owns(id)
+	}
+	return true
+}
+
+// shardMembers returns the shard group members, if sharding is enabled.
+// Must be called with the lock held.
+func (c *Client) shardMembers() []string {
+	if c.sharder == nil {
+		return nil
+	}
+	return c.sharder.Members()
+}
+
+// rescan queues all pending requests again in the background. A rescan
+// requested while one is running is done once it finishes, so a backlog
+// blocking on a full queue doesn't pile up scans.
+func (c *Client) rescan(ctx context.Context, why string) {
+	c.mu.Lock()
+	if c.scanning {
+		c.rescanAgain = true
+		c.mu.Unlock()
+		return
+	}
+	c.scanning = true
+	c.mu.Unlock()
+
+	go func() {
+		for {
+			if err := c.processExistingRequests(ctx); err != nil {
+				c.logger.Error("Failed to process existing requests", "trigger", why, logging.Err(err))
+			}
+
+			c.mu.Lock()
+			again := c.rescanAgain && ctx.Err() == nil
+			c.scanning, c.rescanAgain = again, false
+			c.mu.Unlock()
+			if !again {
+				return
+			}
+		}
+	}()
+}
+
 // RefreshIdentity refreshes the identity file and reconnects
 func (c *Client) RefreshIdentity(ctx context.Context) error {
-	c.logger.Printf("Refreshing identity from %s", c.config.Teleport.Identity)
-
-	creds := client.LoadIdentityFile(c.config.Teleport.Identity)
+	c.logger.Info("Refreshing identity", "identity", c.config.Teleport.Identity)
 
-	newClient, err := client.New(ctx, client.Config{
-		Addrs:       []string{c.config.Teleport.Addr},
-		Credentials: []client.Credentials{creds},
-	})
+	newClient, pong, err := dial(ctx, c.config)
 	if err != nil {
+		c.registry.Error(status.ComponentIdentity, err)
 		c.mu.Lock()
 		c.healthStatus.TeleportConnected = false
 		c.healthStatus.IdentityValid = false
@@ -118,18 +348,35 @@ func (c *Client) RefreshIdentity(ctx context.Context) error {
 	c.healthStatus.LastRefresh = time.Now()
 	c.mu.Unlock()
 
-	c.logger.Printf("Successfully refreshed identity and reconnected")
+	c.logger.Info("Refreshed identity and reconnected", "cluster", pong.GetClusterName(), "server_version", pong.GetServerVersion())
+	c.recordIdentityExpiry()
+
+	// The refreshed identity may carry different roles
+	if err := c.CheckPermissions(ctx); err != nil {
+		c.logger.Warn("Permission self-check failed", logging.Err(err))
+	}
 	return nil
 }
 
-// compileRules compiles all regex patterns for efficient matching
-func (c *Client) compileRules() error {
+// recordIdentityExpiry reads the expiry of the identity in use for health and metrics
+func (c *Client) recordIdentityExpiry() {
+	expiry, err := identityExpiry(c.config.Teleport.Identity)
+	if err != nil {
+		c.logger.Warn("Failed to determine identity expiry", logging.Err(err))
+		return
+	}
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.healthStatus.IdentityExpiry = expiry
+	c.mu.Unlock()
+	metrics.SetTimestamp(metrics.IdentityExpiry, expiry)
+}
 
-	c.compiledRules = make([]*CompiledRule, 0, len(c.config.Rejection.Rules))
+// compileRules compiles all regex patterns for efficient matching
+func compileRules(rules []config.RejectionRule) ([]*CompiledRule, error) {
+	compiledRules := make([]*CompiledRule, 0, len(rules))
 
-	for _, rule := range c.config.Rejection.Rules {
+	for _, rule := range rules {
 		compiledRule := &CompiledRule{
 			Name:    rule.Name,
 			Message: rule.Message,
@@ -139,7 +386,7 @@ func (c *Client) compileRules() error {
 		if rule.ReasonRegex != "" {
 			reasonRegex, err := regexp.Compile(rule.ReasonRegex)
 			if err != nil {
-				return trace.Wrap(err, "failed to compile reason regex for rule %s", rule.Name)
+				return nil, trace.Wrap(err, "failed to compile reason regex for rule %s", rule.Name)
 			}
 			compiledRule.ReasonRegex = reasonRegex
 		}
@@ -148,182 +395,372 @@ func (c *Client) compileRules() error {
 		if rule.RolesRegex != "" {
 			rolesRegex, err := regexp.Compile(rule.RolesRegex)
 			if err != nil {
-				return trace.Wrap(err, "failed to compile roles regex for rule %s", rule.Name)
+				return nil, trace.Wrap(err, "failed to compile roles regex for rule %s", rule.Name)
 			}
 			compiledRule.RolesRegex = rolesRegex
 		}
 
-		c.compiledRules = append(c.compiledRules, compiledRule)
+		compiledRules = append(compiledRules, compiledRule)
 	}
 
-	c.logger.Printf("Compiled %d rejection rules", len(c.compiledRules))
-	return nil
+	return compiledRules, nil
+}
+
+// RunWorkers runs the evaluation worker pool until ctx is cancelled
+func (c *Client) RunWorkers(ctx context.Context) {
+	c.logger.Info("Starting evaluation workers",
+		"workers", c.config.Processing.Workers, "queue_size", c.queue.capacity(), "backpressure", c.config.Processing.Backpressure)
+	c.queue.run(ctx, func(ctx context.Context, t task) {
+		c.processRequest(ctx, t.req, t.source)
+	})
 }
 
-// WatchAccessRequests watches for access requests and rejects them based on configured rules
-func (c *Client) WatchAccessRequests(ctx context.Context) error {
-	watcher, err := c.NewWatcher(ctx, types.Watch{
-		Kinds: []types.WatchKind{
-			{
-				Kind: types.KindAccessRequest,
+// watch runs a single watcher session, queueing access requests for
+// evaluation until the watcher fails. It reports whether the watcher was
+// established before it failed.
+func (c *Client) watch(ctx context.Context) (bool, error) {
+	var watcher types.Watcher
+	err := apiCall(ctx, "NewWatcher", func(context.Context) error {
+		// The watcher outlives the call span, so it gets the session context
+		var err error
+		watcher, err = c.NewWatcher(ctx, types.Watch{
+			Kinds: []types.WatchKind{
+				{
+					Kind: types.KindAccessRequest,
+				},
 			},
-		},
+		})
+		return err
 	})
 	if err != nil {
 		c.mu.Lock()
 		c.healthStatus.TeleportConnected = false
 		c.mu.Unlock()
-		return trace.Wrap(err)
+		return false, trace.Wrap(err)
 	}
 	defer watcher.Close()
+	defer c.setWatchReady(false)
 
-	c.logger.Printf("Started watching access requests")
+	heartbeat := time.NewTicker(loopHeartbeat)
+	defer heartbeat.Stop()
 
-	// First, let's check for existing pending requests
-	c.logger.Printf("Checking for existing pending access requests...")
-	if err := c.processExistingRequests(ctx); err != nil {
-		c.logger.Printf("Error processing existing requests: %v", err)
-	}
+	c.logger.Info("Started watching access requests")
+	established := false
 
 	for {
+		c.beat()
 		select {
+		case <-heartbeat.C:
+
 		case event := <-watcher.Events():
-			c.logger.Printf("Received event: Type=%s, Kind=%s", event.Type, event.Resource.GetKind())
+			metrics.SetTimestamp(metrics.LastEvent, time.Now())
+			c.recordWatchActivity()
+			if event.Type == types.OpInit {
+				c.logger.Info("Access request watcher established")
+				established = true
+
+				c.mu.Lock()
+				c.healthStatus.TeleportConnected = true
+				c.mu.Unlock()
+				c.setWatchReady(true)
+
+				// Check for requests created while we were not watching. The
+				// scan may block on a full queue, so it must not hold up the
+				// event loop.
+				c.rescan(ctx, "watcher established")
+				continue
+			}
+
+			c.logger.Debug("Received event", "type", event.Type, "kind", event.Resource.GetKind(), logging.KeyRequestID, event.Resource.GetName())
+
+			if event.Type == types.OpDelete {
+				c.processed.evict(event.Resource.GetName())
+				continue
+			}
 
 			if event.Type != types.OpPut {
-				c.logger.Printf("Ignoring event type: %s", event.Type)
+				c.logger.Debug("Ignoring event", "type", event.Type)
 				continue
 			}
 
 			req, ok := event.Resource.(types.AccessRequest)
 			if !ok {
-				c.logger.Printf("Event resource is not an AccessRequest: %T", event.Resource)
+				c.logger.Warn("Event resource is not an access request", "resource_type", fmt.Sprintf("%T", event.Resource))
 				continue
 			}
 
-			c.logger.Printf("Processing access request %s, state: %s, reason: %s", req.GetName(), req.GetState(), req.GetRequestReason())
-
 			if req.GetState() != types.RequestState_PENDING {
-				c.logger.Printf("Request %s is not pending (state: %s), ignoring", req.GetName(), req.GetState())
+				c.logger.Debug("Ignoring request that is not pending", logging.KeyRequestID, req.GetName(), "state", req.GetState())
 				continue
 			}
 
-			// Update last request time
-			c.mu.Lock()
-			c.lastRequestTime = time.Now()
-			c.mu.Unlock()
-
-			// Check if request should be rejected
-			if rule := c.shouldReject(req); rule != nil {
-				if err := c.rejectRequest(ctx, req, rule); err != nil {
-					c.logger.Printf("Failed to reject request %s: %v", req.GetName(), err)
-				} else {
-					c.logger.Printf("Rejected request %s using rule '%s': %s", req.GetName(), rule.Name, rule.Message)
-				}
-			} else {
-				c.logger.Printf("Request %s does not match any rejection rules, allowing to proceed", req.GetName())
+			if err := c.queue.enqueue(ctx, task{req: req, source: "watch"}); err != nil {
+				c.logger.Warn("Failed to queue request", logging.KeyRequestID, req.GetName(), logging.Err(err))
 			}
 
+		case <-watcher.Done():
+			return established, trace.Wrap(watcher.Error(), "access request watcher closed")
+
 		case <-ctx.Done():
-			return ctx.Err()
+			return established, ctx.Err()
 		}
 	}
 }
 
-// processExistingRequests checks for existing pending requests and processes them
+// processExistingRequests queues existing pending requests for evaluation,
+// oldest first, skipping requests older than the configured cutoff and
+// requests owned by other replicas
 func (c *Client) processExistingRequests(ctx context.Context) error {
-	requests, err := c.GetAccessRequests(ctx, types.AccessRequestFilter{
-		State: types.RequestState_PENDING,
+	queued := 0
+	total, skipped, err := c.listBacklog(ctx, func(req types.AccessRequest) error {
+		if !c.owns(req.GetName()) {
+			return nil
+		}
+		if err := c.queue.enqueue(ctx, task{req: req, source: "backlog"}); err != nil {
+			c.logger.Warn("Failed to queue request", logging.KeyRequestID, req.GetName(), logging.KeySource, "backlog", logging.Err(err))
+			return nil
+		}
+		queued++
+		return nil
 	})
 	if err != nil {
 		return trace.Wrap(err, "failed to get existing access requests")
 	}
 
-	c.logger.Printf("Found %d existing pending requests", len(requests))
+	c.logger.Info("Scanned existing pending requests",
+		"total", total, "queued", queued, "skipped", skipped, "max_age", c.config.Backlog.MaxAge)
+	return nil
+}
 
-	for _, req := range requests {
-		c.logger.Printf("Processing existing request %s, reason: %s", req.GetName(), req.GetRequestReason())
+// processRequest evaluates a single pending request and rejects it if a rule matches
+func (c *Client) processRequest(ctx context.Context, req types.AccessRequest, source string) {
+	log := c.requestLogger(req).With(logging.KeySource, source)
 
-		// Update last request time
-		c.mu.Lock()
-		c.lastRequestTime = time.Now()
-		c.mu.Unlock()
+	if !c.owns(req.GetName()) {
+		log.Debug("Leaving request to another replica", "role", c.Role())
+		return
+	}
 
-		// Check if request should be rejected
-		if rule := c.shouldReject(req); rule != nil {
-			if err := c.rejectRequest(ctx, req, rule); err != nil {
-				c.logger.Printf("Failed to reject existing request %s: %v", req.GetName(), err)
-			} else {
-				c.logger.Printf("Rejected existing request %s using rule '%s': %s", req.GetName(), rule.Name, rule.Message)
-			}
-		} else {
-			c.logger.Printf("Existing request %s does not match any rejection rules, allowing to proceed", req.GetName())
+	if !c.processed.claim(req.GetName(), req.GetRevision()) {
+		log.Debug("Request revision was already decided, skipping", "revision", req.GetRevision())
+		return
+	}
+
+	decisionID := newDecisionID()
+	span := oteltrace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String(logging.KeyDecisionID, decisionID))
+	log = withTraceID(log.With(logging.KeyDecisionID, decisionID), span)
+	log.Info("Processing request", "roles", req.GetRoles())
+	log.Debug("Request reason", redact.ReasonKey, req.GetRequestReason())
+
+	// Update last request time
+	c.mu.Lock()
+	c.lastRequestTime = time.Now()
+	c.mu.Unlock()
+
+	start := time.Now()
+	decision := &Decision{ID: decisionID, Source: source, TraceID: tracing.TraceID(span)}
+	outcome, err := c.decide(ctx, req, decision, log)
+	metrics.EvaluationDuration.Observe(time.Since(start).Seconds())
+
+	ruleName := decision.Rule
+	decision.Outcome = outcome
+	c.recordDecision(decision, req, start, err)
+	c.recordOutcome(outcome, ruleName)
+	span.SetAttributes(
+		attribute.String(logging.KeyOutcome, string(outcome)),
+		attribute.String(logging.KeyRule, ruleName),
+	)
+	recordSpanError(span, err)
+
+	log = log.With(logging.KeyOutcome, outcome)
+	switch {
+	case outcome == OutcomeError:
+		// Let a later event or scan retry the decision
+		c.processed.release(req.GetName(), req.GetRevision())
+		log.Error("Failed to reject request", logging.KeyRule, ruleName, logging.Err(err))
+	case outcome == OutcomeDenied:
+		log.Info("Rejected request", logging.KeyRule, ruleName)
+	case outcome.Benign():
+		log.Info("Request matched a rule but was no longer reviewable, leaving it as is", logging.KeyRule, ruleName)
+	default:
+		log.Info("Request does not match any rejection rules, allowing it to proceed")
+	}
+}
+
+// decide evaluates the request and, if a rule matches, denies it unless it was
+// resolved or deleted in the meantime. The decision is filled in with the
+// rule, message and checks.
+func (c *Client) decide(ctx context.Context, req types.AccessRequest, d *Decision, log *slog.Logger) (Outcome, error) {
+	rule, checks := c.shouldReject(ctx, req, log)
+	d.Checks = checks
+	if rule == nil {
+		return OutcomeAllowed, nil
+	}
+	d.Rule = rule.Name
+	d.Message = c.ruleMessage(rule)
+
+	outcome, err := c.guardedDeny(ctx, req, func() error {
+		return c.denyRequest(ctx, req, d.Message)
+	})
+	return outcome, trace.Wrap(err)
+}
+
+// guardedDeny runs deny unless the request was resolved or deleted in the
+// meantime, and classifies a failed denial that lost a race with a human.
+// It returns OutcomeDenied if deny succeeded.
+func (c *Client) guardedDeny(ctx context.Context, req types.AccessRequest, deny func() error) (Outcome, error) {
+	// The event may be stale, check the current state before acting
+	outcome, err := c.resolvedOutcome(ctx, req.GetName())
+	if err != nil {
+		return OutcomeError, trace.Wrap(err)
+	}
+	if outcome != "" {
+		return outcome, nil
+	}
+
+	if err := deny(); err != nil {
+		// A human may have won the race between the check and the review
+		if outcome, stateErr := c.resolvedOutcome(ctx, req.GetName()); stateErr == nil && outcome != "" {
+			return outcome, nil
 		}
+		return OutcomeError, trace.Wrap(err)
 	}
 
-	return nil
+	return OutcomeDenied, nil
+}
+
+// RuleCheck is the result of checking a single rejection rule against a request
+type RuleCheck struct {
+	Rule    string `json:"rule"`
+	Applies bool   `json:"applies"`
+	Reject  bool   `json:"reject"`
+	Detail  string `json:"detail"`
 }
 
 // shouldReject checks if a request should be rejected based on configured rules
 // Uses two-stage filtering: 1) Role filter (does rule apply?), 2) Reason check (should reject?)
-func (c *Client) shouldReject(req types.AccessRequest) *CompiledRule {
+func (c *Client) shouldReject(ctx context.Context, req types.AccessRequest, log *slog.Logger) (*CompiledRule, []RuleCheck) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	rules := c.compiledRules
+	c.mu.RUnlock()
 
-	for _, rule := range c.compiledRules {
-		// Stage 1: Role Filter - Does this rule apply to this request?
-		ruleApplies := false
-		if rule.RolesRegex != nil {
-			// Rule has role filter - check if any requested role matches
-			for _, role := range req.GetRoles() {
-				if rule.RolesRegex.MatchString(role) {
-					ruleApplies = true
-					c.logger.Printf("Rule '%s' applies to request %s - role '%s' matches pattern '%s'",
-						rule.Name, req.GetName(), role, rule.RolesRegex.String())
-					break
-				}
-			}
-			if !ruleApplies {
-				c.logger.Printf("Rule '%s' does not apply to request %s - no roles %v match pattern '%s'",
-					rule.Name, req.GetName(), req.GetRoles(), rule.RolesRegex.String())
-				continue // Skip this rule, doesn't apply to these roles
-			}
-		} else {
-			// No role filter - rule applies to all requests
-			ruleApplies = true
-			c.logger.Printf("Rule '%s' applies to request %s - no role filter specified",
-				rule.Name, req.GetName())
+	ctx, span := tracer.Start(ctx, "evaluate.rules", oteltrace.WithAttributes(attribute.Int("rules", len(rules))))
+	defer span.End()
+
+	return matchRule(ctx, rules, req, log)
+}
+
+// matchRule returns the first of rules that rejects the request, or nil,
+// along with the checks of every rule evaluated up to it. Every rule check is
+// logged at debug level and traced as its own span.
+func matchRule(ctx context.Context, rules []*CompiledRule, req types.AccessRequest, log *slog.Logger) (*CompiledRule, []RuleCheck) {
+	checks := make([]RuleCheck, 0, len(rules))
+	for _, rule := range rules {
+		check := evaluateRule(ctx, rule, req, log)
+		checks = append(checks, check)
+		if check.Reject {
+			return rule, checks
 		}
+	}
+
+	return nil, checks // Allow: no rules triggered rejection
+}
 
-		// Stage 2: Reason Check - Should we reject based on reason?
-		if rule.ReasonRegex != nil {
-			if !rule.ReasonRegex.MatchString(req.GetRequestReason()) {
-				c.logger.Printf("Request %s reason '%s' does NOT match required pattern '%s' in rule '%s' - rejecting",
-					req.GetName(), req.GetRequestReason(), rule.ReasonRegex.String(), rule.Name)
-				return rule // Reject: reason doesn't match required pattern
-			} else {
-				c.logger.Printf("Request %s reason '%s' matches required pattern '%s' in rule '%s' - allowing",
-					req.GetName(), req.GetRequestReason(), rule.ReasonRegex.String(), rule.Name)
+// evaluateRule checks whether a single rule rejects the request
+func evaluateRule(ctx context.Context, rule *CompiledRule, req types.AccessRequest, log *slog.Logger) (check RuleCheck) {
+	_, span := tracer.Start(ctx, "evaluate.rule", oteltrace.WithAttributes(attribute.String(logging.KeyRule, rule.Name)))
+	defer func() {
+		span.SetAttributes(attribute.Bool("reject", check.Reject))
+		span.End()
+	}()
+	check.Rule = rule.Name
+
+	// Stage 1: Role Filter - Does this rule apply to this request?
+	if rule.RolesRegex != nil {
+		// Rule has role filter - check if any requested role matches
+		for _, role := range req.GetRoles() {
+			if rule.RolesRegex.MatchString(role) {
+				check.Applies = true
+				check.Detail = fmt.Sprintf("role %q matches %q", role, rule.RolesRegex.String())
+				log.Debug("Rule applies, role matches pattern",
+					logging.KeyRule, rule.Name, "role", role, "pattern", rule.RolesRegex.String())
+				break
 			}
+		}
+		if !check.Applies {
+			check.Detail = fmt.Sprintf("no role matches %q", rule.RolesRegex.String())
+			log.Debug("Rule does not apply, no role matches pattern",
+				logging.KeyRule, rule.Name, "roles", req.GetRoles(), "pattern", rule.RolesRegex.String())
+			return check // Skip this rule, doesn't apply to these roles
+		}
+	} else {
+		// No role filter - rule applies to all requests
+		check.Applies = true
+		check.Detail = "no role filter"
+		log.Debug("Rule applies, no role filter specified", logging.KeyRule, rule.Name)
+	}
+
+	// Stage 2: Reason Check - Should we reject based on reason?
+	if rule.ReasonRegex != nil {
+		if !rule.ReasonRegex.MatchString(req.GetRequestReason()) {
+			check.Reject = true
+			check.Detail += fmt.Sprintf(", reason does not match %q", rule.ReasonRegex.String())
+			log.Debug("Reason does not match required pattern, rejecting",
+				logging.KeyRule, rule.Name, "pattern", rule.ReasonRegex.String())
+			return check // Reject: reason doesn't match required pattern
 		} else {
-			c.logger.Printf("Rule '%s' has no reason filter - allowing request %s",
-				rule.Name, req.GetName())
+			check.Detail += fmt.Sprintf(", reason matches %q", rule.ReasonRegex.String())
+			log.Debug("Reason matches required pattern, allowing",
+				logging.KeyRule, rule.Name, "pattern", rule.ReasonRegex.String())
 		}
+	} else {
+		check.Detail += ", no reason filter"
+		log.Debug("Rule has no reason filter, allowing", logging.KeyRule, rule.Name)
 	}
 
-	return nil // Allow: no rules triggered rejection
+	return check
+}
+
+// requestLogger returns a logger carrying the fields identifying the request
+func (c *Client) requestLogger(req types.AccessRequest) *slog.Logger {
+	return c.logger.With(logging.KeyRequestID, req.GetName(), logging.KeyUser, req.GetUser())
 }
 
-// rejectRequest rejects an access request with the specified rule's message
-func (c *Client) rejectRequest(ctx context.Context, req types.AccessRequest, rule *CompiledRule) error {
-	message := rule.Message
-	if message == "" {
-		message = c.config.Rejection.DefaultMessage
+// newDecisionID returns a random ID for a single evaluation of a request
+func newDecisionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ruleMessage returns the denial message of a rule
+func (c *Client) ruleMessage(rule *CompiledRule) string {
+	if rule.Message != "" {
+		return rule.Message
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.Rejection.DefaultMessage
+}
+
+// denyRequest denies an access request with the given message
+func (c *Client) denyRequest(ctx context.Context, req types.AccessRequest, message string) error {
+	// Spread review calls out so a large backlog doesn't burst the auth server
+	_, span := tracer.Start(ctx, "review.rate_limit")
+	err := c.reviewLimiter.Wait(ctx)
+	recordSpanError(span, err)
+	span.End()
+	if err != nil {
+		return trace.Wrap(err, "rate limit wait interrupted")
 	}
 
-	return c.SetAccessRequestState(ctx, types.AccessRequestUpdate{
-		RequestID: req.GetName(),
-		State:     types.RequestState_DENIED,
-		Reason:    message,
+	err = apiCall(ctx, "SetAccessRequestState", func(ctx context.Context) error {
+		return c.SetAccessRequestState(ctx, types.AccessRequestUpdate{
+			RequestID: req.GetName(),
+			State:     types.RequestState_DENIED,
+			Reason:    message,
+		})
 	})
+	return trace.Wrap(err)
 }
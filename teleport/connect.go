@@ -0,0 +1,195 @@
+package teleport
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"slices"
+	"time"
+
+	"github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/client/webclient"
+	"github.com/gravitational/teleport/api/identityfile"
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+
+	"teleport-autoreviewer/config"
+)
+
+// Connection modes accepted by the teleport.mode setting
+const (
+	// ModeAuto lets the API client race every connection method against every address
+	ModeAuto = "auto"
+	// ModeAuth connects directly to an auth server
+	ModeAuth = "auth"
+	// ModeProxy connects through a web proxy, either over the reverse tunnel or with TLS routing
+	ModeProxy = "proxy"
+)
+
+// dial connects to the cluster with the configured connection options, pings it
+// and checks the result against the expected cluster name
+func dial(ctx context.Context, cfg *config.Config) (*client.Client, proto.PingResponse, error) {
+	if err := verifyCAPins(cfg.Teleport.Identity, cfg.Teleport.CAPins); err != nil {
+		return nil, proto.PingResponse{}, trace.Wrap(err)
+	}
+
+	c, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, proto.PingResponse{}, trace.Wrap(err)
+	}
+
+	var pong proto.PingResponse
+	err = apiCall(ctx, "Ping", func(ctx context.Context) error {
+		var err error
+		pong, err = c.Ping(ctx)
+		return err
+	})
+	if err != nil {
+		c.Close()
+		return nil, proto.PingResponse{}, trace.Wrap(err, "failed to ping Teleport cluster")
+	}
+
+	if expected := cfg.Teleport.ClusterName; expected != "" && pong.GetClusterName() != expected {
+		c.Close()
+		return nil, proto.PingResponse{}, trace.AccessDenied("connected to cluster %q, expected %q", pong.GetClusterName(), expected)
+	}
+
+	return c, pong, nil
+}
+
+// connect creates the API client. In auto mode all addresses are handed to the
+// API client at once, otherwise each address is tried in order with the selected mode
+func connect(ctx context.Context, cfg *config.Config) (*client.Client, error) {
+	creds := client.LoadIdentityFile(cfg.Teleport.Identity)
+
+	if cfg.Teleport.Mode == ModeAuto {
+		c, err := client.New(ctx, client.Config{
+			Addrs:                    cfg.Teleport.Addrs,
+			Credentials:              []client.Credentials{creds},
+			DialTimeout:              cfg.Teleport.DialTimeout,
+			InsecureAddressDiscovery: cfg.Teleport.InsecureAddressDiscovery,
+		})
+		return c, trace.Wrap(err)
+	}
+
+	var errs []error
+	for _, addr := range cfg.Teleport.Addrs {
+		c, err := connectAddr(ctx, cfg, creds, addr)
+		if err == nil {
+			return c, nil
+		}
+		errs = append(errs, trace.Wrap(err, "failed to connect to %s in %s mode", addr, cfg.Teleport.Mode))
+	}
+
+	return nil, trace.NewAggregate(errs...)
+}
+
+// connectAddr connects to a single address using the configured mode
+func connectAddr(ctx context.Context, cfg *config.Config, creds client.Credentials, addr string) (*client.Client, error) {
+	insecure := cfg.Teleport.InsecureAddressDiscovery
+	clientCfg := client.Config{
+		Addrs:                    []string{addr},
+		Credentials:              []client.Credentials{authOnlyCredentials{creds}},
+		DialTimeout:              cfg.Teleport.DialTimeout,
+		InsecureAddressDiscovery: insecure,
+	}
+
+	switch {
+	case cfg.Teleport.Mode == ModeAuth:
+		// Direct auth connection, nothing else to configure
+
+	case cfg.Teleport.TLSRouting:
+		// TLS routing dials the auth server through the proxy web listener,
+		// selecting it with ALPN/SNI, so it only needs the TLS credentials
+		clusterName := cfg.Teleport.ClusterName
+		if clusterName == "" {
+			resp, err := webclient.Find(&webclient.Config{Context: ctx, ProxyAddr: addr, Insecure: insecure})
+			if err != nil {
+				return nil, trace.Wrap(err, "failed to discover cluster name from proxy")
+			}
+			clusterName = resp.ClusterName
+		}
+		clientCfg.ALPNSNIAuthDialClusterName = clusterName
+		clientCfg.ALPNConnUpgradeRequired = client.IsALPNConnUpgradeRequired(ctx, addr, insecure)
+
+	default:
+		// Reverse tunnel through the proxy. The dialer knows the address, so
+		// the client must not also try it as an auth server
+		sshConfig, err := creds.SSHClientConfig()
+		if err != nil {
+			return nil, trace.Wrap(err, "proxy mode requires SSH credentials in the identity file")
+		}
+		clientCfg.Addrs = nil
+		clientCfg.Dialer = client.NewProxyDialer(*sshConfig, 0, cfg.Teleport.DialTimeout, addr, insecure)
+	}
+
+	c, err := client.New(ctx, clientCfg)
+	return c, trace.Wrap(err)
+}
+
+// authOnlyCredentials hides the SSH half of the identity so the API client
+// only uses the connection method selected by the configured mode
+type authOnlyCredentials struct {
+	client.Credentials
+}
+
+// SSHClientConfig reports SSH connections as unavailable
+func (authOnlyCredentials) SSHClientConfig() (*ssh.ClientConfig, error) {
+	return nil, trace.NotImplemented("SSH connection methods are disabled by the configured mode")
+}
+
+// identityExpiry returns when the TLS certificate of the identity file expires
+func identityExpiry(identityPath string) (time.Time, error) {
+	id, err := identityfile.ReadFile(identityPath)
+	if err != nil {
+		return time.Time{}, trace.Wrap(err, "failed to read identity file")
+	}
+
+	block, _ := pem.Decode(id.Certs.TLS)
+	if block == nil {
+		return time.Time{}, trace.BadParameter("identity file contains no TLS certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, trace.Wrap(err, "failed to parse TLS certificate from identity file")
+	}
+	return cert.NotAfter, nil
+}
+
+// verifyCAPins checks that every TLS certificate authority trusted by the
+// identity file matches one of the configured pins
+func verifyCAPins(identityPath string, pins []string) error {
+	if len(pins) == 0 {
+		return nil
+	}
+
+	id, err := identityfile.ReadFile(identityPath)
+	if err != nil {
+		return trace.Wrap(err, "failed to read identity file for CA pin verification")
+	}
+
+	found := 0
+	for _, data := range id.CACerts.TLS {
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return trace.Wrap(err, "failed to parse CA certificate from identity file")
+			}
+			found++
+
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			pin := "sha256:" + hex.EncodeToString(sum[:])
+			if !slices.Contains(pins, pin) {
+				return trace.AccessDenied("identity file trusts CA %q with pin %s which is not in ca_pins", cert.Subject.CommonName, pin)
+			}
+		}
+	}
+
+	if found == 0 {
+		return trace.BadParameter("identity file contains no TLS CA certificates to verify against ca_pins")
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package teleport
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+)
+
+// Decision is the record of a single evaluation of an access request
+type Decision struct {
+	ID           string      `json:"id"`
+	Time         time.Time   `json:"time"`
+	RequestID    string      `json:"request_id"`
+	User         string      `json:"user"`
+	Roles        []string    `json:"roles"`
+	Reason       string      `json:"reason,omitempty"`
+	Revision     string      `json:"revision,omitempty"`
+	State        string      `json:"state"`
+	Created      time.Time   `json:"created"`
+	Expires      time.Time   `json:"expires"`
+	Source       string      `json:"source"`
+	Outcome      Outcome     `json:"outcome"`
+	Rule         string      `json:"rule,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	Checks       []RuleCheck `json:"checks,omitempty"`
+	RulesVersion string      `json:"rules_version"`
+	TraceID      string      `json:"trace_id,omitempty"`
+	Duration     string      `json:"duration"`
+}
+
+// DecisionFilter selects decisions. Empty fields match every decision.
+type DecisionFilter struct {
+	RequestID string
+	User      string
+	Rule      string
+	Outcome   Outcome
+	Since     time.Time
+	// Limit caps the number of decisions returned, newest first
+	Limit int
+}
+
+// Matches reports whether the decision passes the filter
+func (f DecisionFilter) Matches(d *Decision) bool {
+	return (f.RequestID == "" || d.RequestID == f.RequestID) &&
+		(f.User == "" || d.User == f.User) &&
+		(f.Rule == "" || d.Rule == f.Rule) &&
+		(f.Outcome == "" || d.Outcome == f.Outcome) &&
+		(f.Since.IsZero() || !d.Time.Before(f.Since))
+}
+
+// subscriberBuffer is how many decisions a subscriber may fall behind before
+// decisions are dropped for it
+const subscriberBuffer = 64
+
+// Subscription receives new decisions as they are made
+type Subscription struct {
+	// C receives the decisions. It is closed when the subscription ends.
+	C       <-chan Decision
+	ch      chan Decision
+	dropped atomic.Uint64
+}
+
+// Dropped returns and resets the number of decisions dropped because the
+// subscriber fell behind
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+// DecisionJournal durably records every decision
+type DecisionJournal interface {
+	Append(record any) error
+}
+
+// SetJournal makes every decision from now on be written to journal
+func (c *Client) SetJournal(journal DecisionJournal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.journal = journal
+}
+
+// decisionLog keeps the most recent decisions in a ring buffer and passes
+// new ones on to subscribers
+type decisionLog struct {
+	mu          sync.RWMutex
+	decisions   []*Decision
+	next        int
+	full        bool
+	subscribers map[*Subscription]struct{}
+}
+
+// newDecisionLog creates a log keeping the last size decisions
+func newDecisionLog(size int) *decisionLog {
+	return &decisionLog{
+		decisions:   make([]*Decision, size),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// add records a decision, evicting the oldest one when the log is full, and
+// sends it to every subscriber that keeps up
+func (l *decisionLog) add(d *Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for sub := range l.subscribers {
+		select {
+		case sub.ch <- *d:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+	if len(l.decisions) == 0 {
+		return
+	}
+	l.decisions[l.next] = d
+	l.next = (l.next + 1) % len(l.decisions)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// subscribe returns a subscription to new decisions that ends when ctx is done
+func (l *decisionLog) subscribe(ctx context.Context) *Subscription {
+	ch := make(chan Decision, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch}
+
+	l.mu.Lock()
+	l.subscribers[sub] = struct{}{}
+	l.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		delete(l.subscribers, sub)
+		close(ch)
+		l.mu.Unlock()
+	}()
+	return sub
+}
+
+// list returns the decisions matching the filter, newest first
+func (l *decisionLog) list(filter DecisionFilter) []Decision {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	count := l.next
+	if l.full {
+		count = len(l.decisions)
+	}
+	var result []Decision
+	for i := 1; i <= count; i++ {
+		d := l.decisions[(l.next-i+len(l.decisions))%len(l.decisions)]
+		if !filter.Matches(d) {
+			continue
+		}
+		result = append(result, *d)
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// get returns the decision with the given ID
+func (l *decisionLog) get(id string) (Decision, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, d := range l.decisions {
+		if d != nil && d.ID == id {
+			return *d, nil
+		}
+	}
+	return Decision{}, trace.NotFound("decision %q not found, it may have been evicted from the history", id)
+}
+
+// Decisions returns the recent decisions matching the filter, newest first
+func (c *Client) Decisions(filter DecisionFilter) []Decision {
+	return c.history.list(filter)
+}
+
+// SubscribeDecisions returns a subscription to decisions made from now on.
+// It ends when ctx is done.
+func (c *Client) SubscribeDecisions(ctx context.Context) *Subscription {
+	return c.history.subscribe(ctx)
+}
+
+// Decision returns a recent decision by ID
+func (c *Client) Decision(id string) (Decision, error) {
+	return c.history.get(id)
+}
+
+// recordDecision completes a decision about req, writes it to the journal if
+// there is one and adds it to the history. The request reason is redacted
+// before it is stored.
+func (c *Client) recordDecision(d *Decision, req types.AccessRequest, start time.Time, err error) {
+	d.Time = start
+	d.RequestID = req.GetName()
+	d.User = req.GetUser()
+	d.Roles = slices.Clone(req.GetRoles())
+	d.Reason = c.redactor.Reason(req.GetRequestReason())
+	d.Revision = req.GetRevision()
+	d.State = req.GetState().String()
+	d.Created = req.GetCreationTime()
+	d.Expires = req.GetAccessExpiry()
+	d.RulesVersion = c.RulesVersion()
+	d.Duration = time.Since(start).String()
+	if err != nil {
+		d.Error = c.redactor.String(err.Error())
+	}
+
+	c.mu.RLock()
+	journal := c.journal
+	c.mu.RUnlock()
+	if journal != nil {
+		// Written before the decision is visible anywhere else, so the journal
+		// holds everything the admin API ever showed
+		if err := journal.Append(d); err != nil {
+			c.logger.Error("Failed to write decision to the journal",
+				logging.KeyDecisionID, d.ID, logging.KeyRequestID, d.RequestID, logging.Err(err))
+		}
+	}
+	c.history.add(d)
+}
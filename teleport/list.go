@@ -0,0 +1,98 @@
+package teleport
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/metrics"
+)
+
+// listPending calls fn for every pending access request, oldest first, one
+// page at a time. It returns the number of requests listed.
+func (c *Client) listPending(ctx context.Context, fn func(req types.AccessRequest) error) (int, error) {
+	count := 0
+	startKey := ""
+	for {
+		var resp *proto.ListAccessRequestsResponse
+		err := apiCall(ctx, "ListAccessRequests", func(ctx context.Context) error {
+			var err error
+			resp, err = c.ListAccessRequests(ctx, &proto.ListAccessRequestsRequest{
+				Filter:   &types.AccessRequestFilter{State: types.RequestState_PENDING},
+				Sort:     proto.AccessRequestSort_CREATED,
+				Limit:    int32(c.config.Backlog.PageSize),
+				StartKey: startKey,
+			})
+			return err
+		})
+		if trace.IsNotImplemented(err) {
+			// Older auth servers can't paginate
+			return c.listPendingUnpaginated(ctx, fn)
+		}
+		if err != nil {
+			return count, trace.Wrap(err, "failed to list pending access requests")
+		}
+
+		if len(resp.AccessRequests) > 0 {
+			metrics.SetTimestamp(metrics.LastEvent, time.Now())
+		}
+		for _, req := range resp.AccessRequests {
+			count++
+			if err := fn(req); err != nil {
+				return count, trace.Wrap(err)
+			}
+		}
+
+		if resp.NextKey == "" {
+			return count, nil
+		}
+		startKey = resp.NextKey
+	}
+}
+
+// listBacklog calls fn for every pending access request like listPending,
+// skipping requests older than backlog.max_age. Every path that reviews
+// listed requests goes through it, so an old backlog skipped at startup is
+// not picked up by reconciliation, polling or counted by a reload. It
+// returns the number of requests listed and skipped.
+func (c *Client) listBacklog(ctx context.Context, fn func(req types.AccessRequest) error) (int, int, error) {
+	maxAge := c.config.Backlog.MaxAge
+	skipped := 0
+	total, err := c.listPending(ctx, func(req types.AccessRequest) error {
+		if maxAge > 0 && time.Since(req.GetCreationTime()) > maxAge {
+			skipped++
+			return nil
+		}
+		return fn(req)
+	})
+	return total, skipped, trace.Wrap(err)
+}
+
+// listPendingUnpaginated lists all pending requests in a single call, oldest first
+func (c *Client) listPendingUnpaginated(ctx context.Context, fn func(req types.AccessRequest) error) (int, error) {
+	var requests []types.AccessRequest
+	err := apiCall(ctx, "GetAccessRequests", func(ctx context.Context) error {
+		var err error
+		requests, err = c.GetAccessRequests(ctx, types.AccessRequestFilter{
+			State: types.RequestState_PENDING,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, trace.Wrap(err, "failed to get pending access requests")
+	}
+
+	slices.SortFunc(requests, func(a, b types.AccessRequest) int {
+		return a.GetCreationTime().Compare(b.GetCreationTime())
+	})
+	for i, req := range requests {
+		if err := fn(req); err != nil {
+			return i + 1, trace.Wrap(err)
+		}
+	}
+	return len(requests), nil
+}
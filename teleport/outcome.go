@@ -0,0 +1,113 @@
+package teleport
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/metrics"
+)
+
+// Outcome is the result of processing a single access request
+type Outcome string
+
+const (
+	// OutcomeDenied means the service denied the request
+	OutcomeDenied Outcome = "denied"
+	// OutcomeAllowed means no rule matched and the request was left pending
+	OutcomeAllowed Outcome = "allowed"
+	// OutcomeSLADenied means the request was denied for staying pending too long
+	OutcomeSLADenied Outcome = "sla_denied"
+	// OutcomeEscalated means the request stayed pending too long and was escalated
+	OutcomeEscalated Outcome = "escalated"
+	// OutcomeAlreadyApproved means a human approved the request first
+	OutcomeAlreadyApproved Outcome = "already_approved"
+	// OutcomeAlreadyDenied means the request was denied before the service acted
+	OutcomeAlreadyDenied Outcome = "already_denied"
+	// OutcomeDeleted means the request no longer exists
+	OutcomeDeleted Outcome = "deleted"
+	// OutcomeExpired means the request expired before the service acted
+	OutcomeExpired Outcome = "expired"
+	// OutcomeError means the decision could not be applied
+	OutcomeError Outcome = "error"
+)
+
+// Benign reports whether the outcome is a conflict with someone else's
+// action rather than a decision or a failure
+func (o Outcome) Benign() bool {
+	switch o {
+	case OutcomeAlreadyApproved, OutcomeAlreadyDenied, OutcomeDeleted, OutcomeExpired:
+		return true
+	}
+	return false
+}
+
+// resolvedOutcome re-fetches the request and classifies why it can no longer
+// be reviewed. It returns an empty outcome if the request is still pending.
+func (c *Client) resolvedOutcome(ctx context.Context, id string) (Outcome, error) {
+	var requests []types.AccessRequest
+	err := apiCall(ctx, "GetAccessRequests", func(ctx context.Context) error {
+		var err error
+		requests, err = c.GetAccessRequests(ctx, types.AccessRequestFilter{ID: id})
+		return err
+	})
+	if trace.IsNotFound(err) {
+		return OutcomeDeleted, nil
+	}
+	if err != nil {
+		return "", trace.Wrap(err, "failed to re-fetch access request %s", id)
+	}
+	if len(requests) == 0 {
+		return OutcomeDeleted, nil
+	}
+
+	return classifyRequest(requests[0]), nil
+}
+
+// classifyRequest returns the conflict outcome for a request that can no longer
+// be reviewed, or an empty outcome if it is still open for review
+func classifyRequest(req types.AccessRequest) Outcome {
+	switch req.GetState() {
+	case types.RequestState_APPROVED, types.RequestState_PROMOTED:
+		return OutcomeAlreadyApproved
+	case types.RequestState_DENIED:
+		return OutcomeAlreadyDenied
+	}
+
+	if expiry := req.Expiry(); !expiry.IsZero() && expiry.Before(time.Now()) {
+		return OutcomeExpired
+	}
+
+	// Never override a human who already approved, even if the request
+	// still needs more approvals
+	for _, review := range req.GetReviews() {
+		if review.ProposedState == types.RequestState_APPROVED {
+			return OutcomeAlreadyApproved
+		}
+	}
+
+	return ""
+}
+
+// recordOutcome counts an outcome for health reporting and metrics. rule is
+// the name of the rule that decided the request, if any.
+func (c *Client) recordOutcome(outcome Outcome, rule string) {
+	metrics.Decisions.WithLabelValues(string(outcome), rule).Inc()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outcomes[outcome]++
+	if rule != "" {
+		c.ruleHits[rule]++
+	}
+}
+
+// RuleHits returns how many decisions each rule has made since startup
+func (c *Client) RuleHits() map[string]uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.ruleHits)
+}
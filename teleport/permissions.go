@@ -0,0 +1,116 @@
+package teleport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/leader"
+	"teleport-autoreviewer/internal/shard"
+	"teleport-autoreviewer/internal/status"
+)
+
+// Permission is a verb the bot identity needs on a resource kind
+type Permission struct {
+	Kind string
+	Verb string
+}
+
+// String returns the permission in "kind:verb" form
+func (p Permission) String() string {
+	return fmt.Sprintf("%s:%s", p.Kind, p.Verb)
+}
+
+// requiredPermissions returns the permissions needed by the enabled features.
+// Watching needs list and read, denying a request through
+// SetAccessRequestState needs update.
+func (c *Client) requiredPermissions() []Permission {
+	perms := []Permission{
+		{Kind: types.KindAccessRequest, Verb: types.VerbList},
+		{Kind: types.KindAccessRequest, Verb: types.VerbRead},
+		{Kind: types.KindAccessRequest, Verb: types.VerbUpdate},
+	}
+
+	// Leader election leases are Teleport semaphores
+	if c.config.LeaderElection.Enabled && c.config.LeaderElection.Backend == leader.BackendTeleport {
+		perms = append(perms,
+			Permission{Kind: types.KindSemaphore, Verb: types.VerbCreate},
+			Permission{Kind: types.KindSemaphore, Verb: types.VerbUpdate},
+		)
+	}
+
+	// Shard membership is tracked as leases on a Teleport semaphore
+	if c.config.Sharding.Enabled && c.config.Sharding.Backend == shard.BackendTeleport {
+		perms = append(perms,
+			Permission{Kind: types.KindSemaphore, Verb: types.VerbCreate},
+			Permission{Kind: types.KindSemaphore, Verb: types.VerbUpdate},
+			Permission{Kind: types.KindSemaphore, Verb: types.VerbList},
+			Permission{Kind: types.KindSemaphore, Verb: types.VerbRead},
+		)
+	}
+
+	return perms
+}
+
+// CheckPermissions evaluates the roles of the current identity against the
+// required permissions, records the result in the health status and logs
+// every missing permission
+func (c *Client) CheckPermissions(ctx context.Context) error {
+	var roles []types.Role
+	err := apiCall(ctx, "GetCurrentUserRoles", func(ctx context.Context) error {
+		var err error
+		roles, err = c.GetCurrentUserRoles(ctx)
+		return err
+	})
+	if err != nil {
+		err = trace.Wrap(err, "failed to get roles of the current identity")
+		c.registry.Error(status.ComponentPermissions, err)
+		return err
+	}
+
+	var missing []string
+	for _, perm := range c.requiredPermissions() {
+		if !rolesAllow(roles, perm) {
+			missing = append(missing, perm.String())
+			c.logger.Warn("Missing Teleport permission, add it to the bot's role", "kind", perm.Kind, "verb", perm.Verb)
+		}
+	}
+
+	c.mu.Lock()
+	c.healthStatus.MissingPermissions = missing
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		c.logger.Info("Permission self-check passed", "required_permissions", len(c.requiredPermissions()))
+	}
+	return nil
+}
+
+// rolesAllow reports whether any role allows the permission and none denies it.
+// Where clauses are not evaluated, a conditional allow counts as allowed.
+func rolesAllow(roles []types.Role, perm Permission) bool {
+	allowed := false
+	for _, role := range roles {
+		for _, rule := range role.GetRules(types.Deny) {
+			if ruleMatches(rule, perm) {
+				return false
+			}
+		}
+		for _, rule := range role.GetRules(types.Allow) {
+			if ruleMatches(rule, perm) {
+				allowed = true
+			}
+		}
+	}
+	return allowed
+}
+
+// ruleMatches reports whether a role rule covers the permission, including wildcards
+func ruleMatches(rule types.Rule, perm Permission) bool {
+	hasResource := rule.HasResource(perm.Kind) || slices.Contains(rule.Resources, types.Wildcard)
+	hasVerb := rule.HasVerb(perm.Verb) || slices.Contains(rule.Verbs, types.Wildcard)
+	return hasResource && hasVerb
+}
@@ -0,0 +1,197 @@
+package teleport
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+)
+
+// Backpressure modes accepted by the processing.backpressure setting
+const (
+	// BackpressureBlock makes producers wait for room in the queue
+	BackpressureBlock = "block"
+	// BackpressureDrop drops requests that do not fit in the queue
+	BackpressureDrop = "drop"
+)
+
+// task is a single access request waiting to be evaluated
+type task struct {
+	req      types.AccessRequest
+	source   string
+	enqueued time.Time
+	// span covers the task from enqueueing until it was processed
+	span oteltrace.Span
+}
+
+// workQueue is a bounded queue consumed by a fixed pool of workers. Each
+// worker owns a shard of the queue and requests are assigned to shards by ID,
+// so updates to the same request are always processed in order.
+type workQueue struct {
+	shards       []chan task
+	beats        []atomic.Int64
+	timeout      time.Duration
+	backpressure string
+	logger       *slog.Logger
+	dropped      atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[string]int
+}
+
+// newWorkQueue creates a queue holding up to size requests spread over workers shards
+func newWorkQueue(workers, size int, timeout time.Duration, backpressure string, logger *slog.Logger) *workQueue {
+	perShard := max(size/workers, 1)
+	shards := make([]chan task, workers)
+	for i := range shards {
+		shards[i] = make(chan task, perShard)
+	}
+	q := &workQueue{
+		shards:       shards,
+		beats:        make([]atomic.Int64, workers),
+		timeout:      timeout,
+		backpressure: backpressure,
+		logger:       logger,
+		pending:      make(map[string]int),
+	}
+	for i := range q.beats {
+		q.beats[i].Store(time.Now().UnixNano())
+	}
+	return q
+}
+
+// shardFor returns the shard that owns the request ID
+func (q *workQueue) shardFor(id string) chan task {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return q.shards[h.Sum32()%uint32(len(q.shards))]
+}
+
+// enqueue adds a request to its shard, blocking or dropping when the shard is full
+func (q *workQueue) enqueue(ctx context.Context, t task) error {
+	t.enqueued = time.Now()
+	id := t.req.GetName()
+	ch := q.shardFor(id)
+
+	// Each task starts its own trace, the producer's context only lives as
+	// long as the watcher or scan that found the request
+	_, t.span = tracer.Start(context.Background(), "autoreviewer.request",
+		oteltrace.WithTimestamp(t.enqueued),
+		oteltrace.WithAttributes(
+			attribute.String(logging.KeyRequestID, id),
+			attribute.String(logging.KeyUser, t.req.GetUser()),
+			attribute.String(logging.KeySource, t.source),
+		),
+	)
+
+	q.track(id, 1)
+	select {
+	case ch <- t:
+		metrics.QueueDepth.Set(float64(q.depth()))
+		return nil
+	default:
+	}
+
+	if q.backpressure == BackpressureDrop {
+		q.track(id, -1)
+		q.dropped.Add(1)
+		metrics.QueueDropped.Inc()
+		err := trace.LimitExceeded("queue full, dropped request %s", id)
+		recordSpanError(t.span, err)
+		t.span.End()
+		return err
+	}
+
+	q.logger.Warn("Queue full, waiting to enqueue request", logging.KeyRequestID, id)
+	select {
+	case ch <- t:
+		metrics.QueueDepth.Set(float64(q.depth()))
+		return nil
+	case <-ctx.Done():
+		q.track(id, -1)
+		recordSpanError(t.span, ctx.Err())
+		t.span.End()
+		return trace.Wrap(ctx.Err())
+	}
+}
+
+// track adjusts the number of queued or in-flight copies of a request
+func (q *workQueue) track(id string, delta int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[id] += delta
+	if q.pending[id] <= 0 {
+		delete(q.pending, id)
+	}
+}
+
+// isPending reports whether a request is queued or being processed
+func (q *workQueue) isPending(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending[id] > 0
+}
+
+// run starts one worker per shard and blocks until ctx is cancelled and the workers exit
+func (q *workQueue) run(ctx context.Context, handle func(ctx context.Context, t task)) {
+	var wg sync.WaitGroup
+	for i, ch := range q.shards {
+		wg.Add(1)
+		go func(ch chan task, beat *atomic.Int64) {
+			defer wg.Done()
+			heartbeat := time.NewTicker(loopHeartbeat)
+			defer heartbeat.Stop()
+			for {
+				beat.Store(time.Now().UnixNano())
+				select {
+				case <-heartbeat.C:
+				case t := <-ch:
+					metrics.QueueDepth.Set(float64(q.depth()))
+					taskCtx, cancel := context.WithTimeout(oteltrace.ContextWithSpan(ctx, t.span), q.timeout)
+					_, wait := tracer.Start(taskCtx, "queue.wait", oteltrace.WithTimestamp(t.enqueued))
+					wait.End()
+					handle(taskCtx, t)
+					cancel()
+					t.span.End()
+					q.track(t.req.GetName(), -1)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch, &q.beats[i])
+	}
+	wg.Wait()
+}
+
+// stalestBeat returns the oldest time a worker reported it was alive
+func (q *workQueue) stalestBeat() time.Time {
+	stalest := q.beats[0].Load()
+	for i := range q.beats {
+		stalest = min(stalest, q.beats[i].Load())
+	}
+	return time.Unix(0, stalest)
+}
+
+// depth returns the number of queued requests
+func (q *workQueue) depth() int {
+	n := 0
+	for _, ch := range q.shards {
+		n += len(ch)
+	}
+	return n
+}
+
+// capacity returns the total number of requests the queue can hold
+func (q *workQueue) capacity() int {
+	return len(q.shards) * cap(q.shards[0])
+}
@@ -0,0 +1,47 @@
+package teleport
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+)
+
+// Reconcile lists pending requests and queues every one this replica owns but
+// has not decided yet, unless it is older than backlog.max_age, as a safety
+// net for missed events. Requests found this way are drift: the watch loop
+// should have seen them. It returns the number of drifted requests.
+func (c *Client) Reconcile(ctx context.Context) (int, error) {
+	drift := 0
+	total, skipped, err := c.listBacklog(ctx, func(req types.AccessRequest) error {
+		id := req.GetName()
+		if !c.owns(id) || c.queue.isPending(id) || c.processed.has(id, req.GetRevision()) {
+			return nil
+		}
+
+		drift++
+		c.logger.Info("Reconciliation found undecided request", logging.KeyRequestID, id, logging.KeyUser, req.GetUser(), "revision", req.GetRevision())
+		if err := c.queue.enqueue(ctx, task{req: req, source: "reconcile"}); err != nil {
+			c.logger.Warn("Failed to queue request", logging.KeyRequestID, id, logging.Err(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return drift, trace.Wrap(err)
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	c.reconcileDrift += uint64(drift)
+	c.lastReconcile = now
+	c.mu.Unlock()
+	metrics.ReconcileDrift.Add(float64(drift))
+	metrics.ReconcileTimestamp.Set(float64(now.Unix()))
+
+	c.logger.Info("Reconciliation complete", "pending", total, "drift", drift, "skipped", skipped)
+	return drift, nil
+}
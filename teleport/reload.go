@@ -0,0 +1,158 @@
+package teleport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	"gopkg.in/yaml.v2"
+
+	"teleport-autoreviewer/config"
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/status"
+)
+
+// rulesVersion returns a short digest identifying the rejection and SLA rule sets
+func rulesVersion(cfg *config.Config) string {
+	rules, err := yaml.Marshal(struct {
+		Rejection interface{}
+		SLA       interface{}
+	}{cfg.Rejection, cfg.SLA})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(rules)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// heldRules are rules that were not applied because they would deny more
+// pending requests than a reload may without confirmation
+type heldRules struct {
+	version string
+	err     error
+}
+
+// RulesVersion returns the digest of the rule sets currently in effect
+func (c *Client) RulesVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rulesVersion
+}
+
+// ReloadRules replaces the rejection and SLA rules with the ones in cfg. When
+// the rule sets changed, every owned pending request is re-evaluated against
+// the new rules. If more than reload.max_denials requests would be denied, the
+// new rules are not applied and a LimitExceeded error is returned, unless
+// confirm or reload.confirm_version in cfg is their version. Rules held back
+// this way are not re-evaluated again until they are confirmed or changed.
+// Other settings in cfg are ignored.
+func (c *Client) ReloadRules(ctx context.Context, cfg *config.Config, confirm string) error {
+	ctx, span := tracer.Start(ctx, "rules.reload")
+	defer span.End()
+
+	err := c.reloadRules(ctx, cfg, confirm)
+	recordSpanError(span, err)
+	c.registry.Error(status.ComponentRules, err)
+
+	c.mu.Lock()
+	c.lastReload = time.Now()
+	c.lastReloadError = ""
+	if err != nil {
+		c.lastReloadError = err.Error()
+	}
+	c.mu.Unlock()
+
+	metrics.SetTimestamp(metrics.ReloadTimestamp, time.Now())
+	if err != nil {
+		metrics.ReloadSuccess.Set(0)
+	} else {
+		metrics.ReloadSuccess.Set(1)
+	}
+
+	return trace.Wrap(err)
+}
+
+func (c *Client) reloadRules(ctx context.Context, cfg *config.Config, confirm string) error {
+	version := rulesVersion(cfg)
+	c.mu.RLock()
+	current, held := c.rulesVersion, c.heldRules
+	c.mu.RUnlock()
+	if version == current {
+		return nil
+	}
+	confirmed := confirm == version || cfg.Reload.ConfirmVersion == version
+	if held != nil && held.version == version && !confirmed {
+		return trace.Wrap(held.err)
+	}
+
+	rules, err := compileRules(cfg.Rejection.Rules)
+	if err != nil {
+		return trace.Wrap(err, "failed to compile rejection rules")
+	}
+	slaRules, err := compileSLARules(cfg)
+	if err != nil {
+		return trace.Wrap(err, "failed to compile SLA rules")
+	}
+
+	// Find the pending requests the new rules would deny before applying them
+	var matches []types.AccessRequest
+	if _, _, err := c.listBacklog(ctx, func(req types.AccessRequest) error {
+		if !c.owns(req.GetName()) {
+			return nil
+		}
+		if rule, _ := matchRule(ctx, rules, req, c.requestLogger(req)); rule != nil {
+			matches = append(matches, req)
+		}
+		return nil
+	}); err != nil {
+		return trace.Wrap(err, "failed to re-evaluate pending requests")
+	}
+
+	if limit := cfg.Reload.MaxDenials; limit > 0 && len(matches) > limit && !confirmed {
+		for _, req := range matches {
+			c.requestLogger(req).Warn("Reload would deny pending request", "roles", req.GetRoles(), "rules_version", version)
+		}
+		how := "set reload.confirm_version to " + version + " and reload"
+		if c.config.Admin.Enabled {
+			how += ", or POST /admin/reload?confirm=" + version
+		}
+		err := trace.LimitExceeded("new rules %s would deny %d pending requests, more than the %d allowed per reload; to confirm them, %s",
+			version, len(matches), limit, how)
+		c.mu.Lock()
+		c.heldRules = &heldRules{version: version, err: err}
+		c.mu.Unlock()
+		return err
+	}
+
+	c.mu.Lock()
+	previous := c.rulesVersion
+	c.heldRules = nil
+	c.compiledRules = rules
+	c.slaRules = slaRules
+	c.config.Rejection = cfg.Rejection
+	c.config.SLA.DefaultMessage = cfg.SLA.DefaultMessage
+	c.config.SLA.Rules = cfg.SLA.Rules
+	c.rulesVersion = version
+	c.mu.Unlock()
+	metrics.SetRulesVersion(version)
+
+	c.logger.Info("Reloaded rules, re-evaluating pending requests",
+		"rejection_rules", len(rules), "sla_rules", len(slaRules), "previous_version", previous, "rules_version", version, "matches", len(matches))
+
+	// Requests left pending under the old rules were already decided at their
+	// current revision, so forget that decision before queueing them again
+	for _, req := range matches {
+		if c.queue.isPending(req.GetName()) {
+			continue
+		}
+		c.processed.release(req.GetName(), req.GetRevision())
+		if err := c.queue.enqueue(ctx, task{req: req, source: "reevaluate"}); err != nil {
+			c.logger.Warn("Failed to queue request", logging.KeyRequestID, req.GetName(), logging.Err(err))
+		}
+	}
+	return nil
+}
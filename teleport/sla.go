@@ -0,0 +1,235 @@
+package teleport
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"teleport-autoreviewer/config"
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/tracing"
+)
+
+// SLA actions accepted by the sla.rules[].action setting
+const (
+	// SLAActionDeny denies requests pending longer than the rule allows
+	SLAActionDeny = "deny"
+	// SLAActionEscalate reports requests pending longer than the rule allows without denying them
+	SLAActionEscalate = "escalate"
+)
+
+// SLARule is a compiled pending-request SLA policy
+type SLARule struct {
+	Name       string
+	RolesRegex *regexp.Regexp
+	MaxAge     time.Duration
+	Action     string
+	Message    *template.Template
+}
+
+// slaMessageData is the data available to SLA message templates
+type slaMessageData struct {
+	RequestID string
+	User      string
+	Roles     string
+	Rule      string
+	Created   time.Time
+	Age       time.Duration
+	MaxAge    time.Duration
+}
+
+// compileSLARules compiles the role patterns and message templates of the SLA policies
+func compileSLARules(cfg *config.Config) ([]*SLARule, error) {
+	rules := make([]*SLARule, 0, len(cfg.SLA.Rules))
+	for _, rule := range cfg.SLA.Rules {
+		if rule.MaxAge <= 0 {
+			return nil, trace.BadParameter("SLA rule %s must set a positive max_age", rule.Name)
+		}
+		action := rule.Action
+		if action == "" {
+			action = SLAActionDeny
+		}
+		if action != SLAActionDeny && action != SLAActionEscalate {
+			return nil, trace.BadParameter("unknown action %q for SLA rule %s, expected %q or %q",
+				action, rule.Name, SLAActionDeny, SLAActionEscalate)
+		}
+
+		compiled := &SLARule{
+			Name:   rule.Name,
+			MaxAge: rule.MaxAge,
+			Action: action,
+		}
+
+		if rule.RolesRegex != "" {
+			rolesRegex, err := regexp.Compile(rule.RolesRegex)
+			if err != nil {
+				return nil, trace.Wrap(err, "failed to compile roles regex for SLA rule %s", rule.Name)
+			}
+			compiled.RolesRegex = rolesRegex
+		}
+
+		message := rule.Message
+		if message == "" {
+			message = cfg.SLA.DefaultMessage
+		}
+		tmpl, err := template.New(rule.Name).Parse(message)
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to parse message template for SLA rule %s", rule.Name)
+		}
+		compiled.Message = tmpl
+
+		rules = append(rules, compiled)
+	}
+
+	return rules, nil
+}
+
+// SweepStale denies or escalates owned pending requests that are older than
+// the first SLA rule matching their roles. It returns the number of requests
+// acted on.
+func (c *Client) SweepStale(ctx context.Context) (int, error) {
+	acted := 0
+	pending := make(map[string]bool)
+	_, err := c.listPending(ctx, func(req types.AccessRequest) error {
+		id := req.GetName()
+		pending[id] = true
+		if !c.owns(id) {
+			return nil
+		}
+
+		rule := c.slaRuleFor(req)
+		if rule == nil {
+			return nil
+		}
+		age := time.Since(req.GetCreationTime())
+		if age <= rule.MaxAge {
+			return nil
+		}
+
+		if c.applySLA(ctx, req, rule, age) {
+			acted++
+		}
+		return nil
+	})
+	if err != nil {
+		return acted, trace.Wrap(err)
+	}
+
+	// Forget escalations of requests that are no longer pending
+	c.mu.Lock()
+	for id := range c.escalated {
+		if !pending[id] {
+			delete(c.escalated, id)
+		}
+	}
+	metrics.SLAEscalated.Set(float64(len(c.escalated)))
+	c.mu.Unlock()
+
+	if acted > 0 {
+		c.logger.Info("SLA sweep acted on stale requests", "acted", acted)
+	}
+	return acted, nil
+}
+
+// slaRuleFor returns the first SLA rule applying to the request's roles
+func (c *Client) slaRuleFor(req types.AccessRequest) *SLARule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, rule := range c.slaRules {
+		if rule.RolesRegex == nil {
+			return rule
+		}
+		for _, role := range req.GetRoles() {
+			if rule.RolesRegex.MatchString(role) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// applySLA denies or escalates a stale request. It reports whether anything was done.
+func (c *Client) applySLA(ctx context.Context, req types.AccessRequest, rule *SLARule, age time.Duration) bool {
+	id := req.GetName()
+	ctx, span := tracer.Start(ctx, "autoreviewer.sla", oteltrace.WithAttributes(
+		attribute.String(logging.KeyRequestID, id),
+		attribute.String(logging.KeyUser, req.GetUser()),
+		attribute.String(logging.KeyRule, rule.Name),
+		attribute.String("action", rule.Action),
+	))
+	defer span.End()
+
+	start := time.Now()
+	decision := &Decision{ID: newDecisionID(), Source: "sla", Rule: rule.Name, TraceID: tracing.TraceID(span)}
+	span.SetAttributes(attribute.String(logging.KeyDecisionID, decision.ID))
+
+	log := c.requestLogger(req).With(logging.KeyRule, rule.Name, "age", age.Round(time.Minute), "max_age", rule.MaxAge)
+	log = withTraceID(log.With(logging.KeyDecisionID, decision.ID), span)
+
+	if rule.Action == SLAActionEscalate {
+		c.mu.Lock()
+		already := c.escalated[id]
+		c.escalated[id] = true
+		c.mu.Unlock()
+		if already {
+			return false
+		}
+
+		decision.Outcome = OutcomeEscalated
+		c.recordDecision(decision, req, start, nil)
+		c.recordOutcome(OutcomeEscalated, rule.Name)
+		metrics.SLAEscalations.WithLabelValues(rule.Name).Inc()
+		log.Warn("ESCALATION: request has been pending longer than its SLA allows",
+			"roles", req.GetRoles(), logging.KeyOutcome, OutcomeEscalated)
+		return true
+	}
+
+	var message strings.Builder
+	if err := rule.Message.Execute(&message, slaMessageData{
+		RequestID: id,
+		User:      req.GetUser(),
+		Roles:     strings.Join(req.GetRoles(), ", "),
+		Rule:      rule.Name,
+		Created:   req.GetCreationTime(),
+		Age:       age.Round(time.Minute),
+		MaxAge:    rule.MaxAge,
+	}); err != nil {
+		log.Error("Failed to render SLA message", logging.Err(err))
+		return false
+	}
+
+	decision.Message = message.String()
+	outcome, err := c.guardedDeny(ctx, req, func() error {
+		return c.denyRequest(ctx, req, decision.Message)
+	})
+	if outcome == OutcomeDenied {
+		outcome = OutcomeSLADenied
+	}
+	decision.Outcome = outcome
+	c.recordDecision(decision, req, start, err)
+	c.recordOutcome(outcome, rule.Name)
+	span.SetAttributes(attribute.String(logging.KeyOutcome, string(outcome)))
+	recordSpanError(span, err)
+
+	log = log.With(logging.KeyOutcome, outcome)
+	switch {
+	case outcome == OutcomeError:
+		log.Error("Failed to deny stale request", logging.Err(err))
+		return false
+	case outcome.Benign():
+		log.Info("Stale request was no longer reviewable, leaving it as is")
+		return false
+	}
+
+	log.Info("Denied stale request")
+	return true
+}
@@ -0,0 +1,83 @@
+package teleport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/status"
+)
+
+// recordWatchActivity records that the watch stream or a poll heard from
+// Teleport
+func (c *Client) recordWatchActivity() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastWatchActivity = time.Now()
+}
+
+// Probe checks the Teleport connection with a cheap call when the watch
+// stream has been silent for longer than the probe interval. On a quiet
+// cluster this tells an idle stream from a dead one.
+func (c *Client) Probe(ctx context.Context) error {
+	defer c.updateStaleMetric()
+
+	c.mu.RLock()
+	silent := time.Since(c.lastWatchActivity)
+	c.mu.RUnlock()
+	if silent < c.config.Watch.ProbeInterval {
+		return nil
+	}
+
+	err := apiCall(ctx, "Ping", func(ctx context.Context) error {
+		_, err := c.Ping(ctx)
+		return err
+	})
+	if err != nil {
+		err = trace.Wrap(err, "watch stream silent for %v and Teleport probe failed", silent.Round(time.Second))
+		c.registry.Error(status.ComponentStream, err)
+		return err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	c.lastProbe = now
+	c.mu.Unlock()
+	metrics.SetTimestamp(metrics.LastProbe, now)
+	return nil
+}
+
+// updateStaleMetric exports whether the watch stream is stale
+func (c *Client) updateStaleMetric() {
+	c.mu.RLock()
+	stale := c.watchStale()
+	c.mu.RUnlock()
+	if stale {
+		metrics.WatchStale.Set(1)
+	} else {
+		metrics.WatchStale.Set(0)
+	}
+}
+
+// watchStale reports whether the watch stream has been silent longer than
+// the stale threshold without a successful probe. Must be called with the
+// lock held.
+func (c *Client) watchStale() bool {
+	threshold := c.config.Watch.StaleThreshold
+	return time.Since(c.lastWatchActivity) > threshold && time.Since(c.lastProbe) > threshold
+}
+
+// streamCheck fails when the watch stream is stale
+func (c *Client) streamCheck() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	silent := time.Since(c.lastWatchActivity).Round(time.Second)
+	if c.watchStale() {
+		return false, fmt.Sprintf("watch stream silent for %v and no successful probe", silent)
+	}
+	return true, fmt.Sprintf("last activity %v ago", silent)
+}
@@ -0,0 +1,105 @@
+package teleport
+
+import (
+	"fmt"
+	"time"
+
+	"teleport-autoreviewer/internal/status"
+)
+
+// loopHeartbeat is how often long-running loops report that they are alive
+const loopHeartbeat = 10 * time.Second
+
+// registerChecks reports the client's components into the status registry
+func (c *Client) registerChecks() {
+	c.registry.Register(status.ComponentWatchLoop, status.KindLiveness, c.watchLoopCheck)
+	c.registry.Register(status.ComponentWorkers, status.KindLiveness, c.workersCheck)
+	c.registry.Register(status.ComponentWatcher, status.KindReadiness, c.watcherCheck)
+	c.registry.Register(status.ComponentRules, status.KindReadiness, c.rulesCheck)
+	c.registry.Register(status.ComponentIdentity, status.KindReadiness, c.identityCheck)
+	c.registry.Register(status.ComponentStream, status.KindInfo, c.streamCheck)
+	c.registry.Register(status.ComponentPermissions, status.KindInfo, c.permissionsCheck)
+	c.registry.Details("teleport", func() any { return c.GetHealthStatus() })
+}
+
+// beat records that the watch loop is alive
+func (c *Client) beat() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchBeat = time.Now()
+}
+
+// setWatchReady records whether access requests are currently being received
+func (c *Client) setWatchReady(ready bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchReady = ready
+}
+
+// watchLoopCheck fails when the watch loop stopped reporting heartbeats
+func (c *Client) watchLoopCheck() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return loopCheck(c.watchBeat, c.config.Server.LivenessTimeout)
+}
+
+// workersCheck fails when a worker stopped reporting heartbeats
+func (c *Client) workersCheck() (bool, string) {
+	return loopCheck(c.queue.stalestBeat(), c.config.Server.LivenessTimeout)
+}
+
+// loopCheck checks that a loop has reported within timeout
+func loopCheck(last time.Time, timeout time.Duration) (bool, string) {
+	since := time.Since(last).Round(time.Second)
+	if since > timeout {
+		return false, fmt.Sprintf("no heartbeat for %v", since)
+	}
+	return true, fmt.Sprintf("last heartbeat %v ago", since)
+}
+
+// watcherCheck passes once a watcher sent OpInit or a poll succeeded
+func (c *Client) watcherCheck() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.watchReady {
+		return false, fmt.Sprintf("not receiving access requests (mode %s)", c.watchMode)
+	}
+	return true, fmt.Sprintf("receiving access requests (mode %s)", c.watchMode)
+}
+
+// rulesCheck passes when rules are compiled. A failed reload keeps the
+// previous rules in effect, so it is only reported as the last error.
+func (c *Client) rulesCheck() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.rulesVersion == "" {
+		return false, "rules not compiled"
+	}
+	return true, fmt.Sprintf("%d rejection and %d SLA rules compiled (version %s)",
+		len(c.compiledRules), len(c.slaRules), c.rulesVersion)
+}
+
+// identityCheck passes when the identity is valid and not about to expire
+func (c *Client) identityCheck() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.healthStatus.IdentityValid || !c.healthStatus.TeleportConnected {
+		return false, "identity invalid or not connected to Teleport"
+	}
+	expiry := c.healthStatus.IdentityExpiry
+	if expiry.IsZero() {
+		return true, "identity valid, expiry unknown"
+	}
+	left := time.Until(expiry).Round(time.Second)
+	return left >= c.config.Server.IdentityExpiryThreshold, fmt.Sprintf("identity expires in %v", left)
+}
+
+// permissionsCheck passes when the bot's roles grant every required permission
+func (c *Client) permissionsCheck() (bool, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if missing := c.healthStatus.MissingPermissions; len(missing) > 0 {
+		return false, fmt.Sprintf("missing %v", missing)
+	}
+	return true, "all required permissions granted"
+}
@@ -0,0 +1,51 @@
+package teleport
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/tracing"
+)
+
+// tracer creates the spans of request evaluation and Teleport API calls
+var tracer = otel.Tracer("teleport-autoreviewer/teleport")
+
+// apiCall runs a Teleport API call in a client span and records its latency
+// and errors
+func apiCall(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, "teleport."+method,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attribute.String("rpc.method", method)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := call(ctx)
+	metrics.ObserveAPI(method, start, err)
+	recordSpanError(span, err)
+	return err
+}
+
+// withTraceID adds the span's trace ID to the logger, if it has one
+func withTraceID(log *slog.Logger, span oteltrace.Span) *slog.Logger {
+	if traceID := tracing.TraceID(span); traceID != "" {
+		return log.With(logging.KeyTraceID, traceID)
+	}
+	return log
+}
+
+// recordSpanError marks the span as failed if err is set
+func recordSpanError(span oteltrace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,200 @@
+package teleport
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"teleport-autoreviewer/internal/logging"
+	"teleport-autoreviewer/internal/metrics"
+	"teleport-autoreviewer/internal/status"
+)
+
+// Watch modes accepted by the watch.mode setting
+const (
+	// WatchModeWatch streams access request events from a watcher
+	WatchModeWatch = "watch"
+	// WatchModePoll lists pending access requests on an interval
+	WatchModePoll = "poll"
+	// WatchModeAuto watches and falls back to polling after repeated watcher failures
+	WatchModeAuto = "auto"
+)
+
+// maxWatchBackoff caps the delay between watcher reconnection attempts
+const maxWatchBackoff = time.Minute
+
+// WatchAccessRequests feeds access requests into the evaluation pipeline
+// using the configured watch mode until ctx is cancelled. Failed watchers are
+// re-established with backoff. In auto mode repeated failures switch to
+// polling for a while before watching is tried again.
+func (c *Client) WatchAccessRequests(ctx context.Context) error {
+	cfg := c.config.Watch
+	if cfg.Mode == WatchModePoll {
+		return c.pollAccessRequests(ctx, 0)
+	}
+
+	backoff := cfg.RetryInterval
+	for {
+		c.beat()
+		c.setWatchMode(WatchModeWatch)
+		established, err := c.watch(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		c.registry.Error(status.ComponentWatcher, err)
+		failures := c.recordWatchFailure(established)
+		if established {
+			backoff = cfg.RetryInterval
+		}
+		c.logger.Warn("Access request watcher failed", "consecutive_failures", failures, logging.Err(err))
+		metrics.WatcherReconnects.Inc()
+
+		if cfg.Mode == WatchModeAuto && failures >= cfg.MaxFailures {
+			c.logger.Warn("Watcher keeps failing, falling back to polling", "consecutive_failures", failures, "duration", cfg.FallbackDuration)
+			if err := c.pollAccessRequests(ctx, cfg.FallbackDuration); err != nil {
+				return trace.Wrap(err)
+			}
+			c.resetWatchFailures()
+			backoff = cfg.RetryInterval
+			continue
+		}
+
+		if err := c.waitBeating(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, maxWatchBackoff)
+	}
+}
+
+// pollAccessRequests lists pending requests on an interval and queues every
+// new or changed one, until ctx is cancelled or, if duration is set, until
+// duration has passed
+func (c *Client) pollAccessRequests(ctx context.Context, duration time.Duration) error {
+	c.setWatchMode(WatchModePoll)
+	c.logger.Info("Polling access requests", "interval", c.config.Watch.PollInterval)
+
+	var deadline <-chan time.Time
+	if duration > 0 {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ticker := time.NewTicker(c.config.Watch.PollInterval)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(loopHeartbeat)
+	defer heartbeat.Stop()
+
+	// Revisions seen by the previous poll, for change detection
+	seen := make(map[string]string)
+	for {
+		err := c.poll(ctx, seen)
+		c.setWatchReady(err == nil)
+		if err == nil {
+			c.recordWatchActivity()
+		} else {
+			c.registry.Error(status.ComponentWatcher, err)
+			c.logger.Error("Failed to poll access requests", logging.Err(err))
+		}
+
+	wait:
+		for {
+			c.beat()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-deadline:
+				return nil
+			case <-heartbeat.C:
+			case <-ticker.C:
+				break wait
+			}
+		}
+	}
+}
+
+// poll lists pending requests once and queues those that are new or changed
+// since the previous poll, skipping those older than backlog.max_age. A request that could not be queued is not marked
+// as seen, so the next poll tries again.
+func (c *Client) poll(ctx context.Context, seen map[string]string) error {
+	current := make(map[string]string, len(seen))
+	_, _, err := c.listBacklog(ctx, func(req types.AccessRequest) error {
+		id, revision := req.GetName(), req.GetRevision()
+		if prev, ok := seen[id]; ok && prev == revision {
+			current[id] = revision
+			return nil
+		}
+		if err := c.queue.enqueue(ctx, task{req: req, source: "poll"}); err != nil {
+			c.logger.Warn("Failed to queue request, retrying on the next poll", logging.KeyRequestID, id, logging.Err(err))
+			return nil
+		}
+		current[id] = revision
+		return nil
+	})
+	c.mu.Lock()
+	c.healthStatus.TeleportConnected = err == nil
+	c.mu.Unlock()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Requests that are no longer pending were resolved or deleted, or could
+	// not be queued
+	for id := range seen {
+		if _, ok := current[id]; !ok {
+			delete(seen, id)
+		}
+	}
+	for id, revision := range current {
+		seen[id] = revision
+	}
+	return nil
+}
+
+// waitBeating waits for d, keeping the watch loop heartbeat alive
+func (c *Client) waitBeating(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	heartbeat := time.NewTicker(loopHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		c.beat()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-heartbeat.C:
+		}
+	}
+}
+
+// setWatchMode records how access requests are currently received
+func (c *Client) setWatchMode(mode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchMode = mode
+}
+
+// recordWatchFailure counts a watcher failure and returns the number of
+// consecutive failures. A watcher that was established resets the count.
+func (c *Client) recordWatchFailure(established bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if established {
+		c.watchFailures = 0
+	}
+	c.watchFailures++
+	return c.watchFailures
+}
+
+// resetWatchFailures clears the consecutive failure count
+func (c *Client) resetWatchFailures() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchFailures = 0
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"teleport-autoreviewer/internal/journal"
+)
+
+// Exit codes of the verify-journal subcommand
+const (
+	verifyOK      = 0
+	verifyFailed  = 1
+	verifyInvalid = 2
+	// verifyUnverified means no problem was found, but the journal could
+	// not be proven intact either
+	verifyUnverified = 3
+)
+
+// checkpointGrace is how much later than the checkpoint interval the last
+// checkpoint may be before verify-journal warns about it
+const checkpointGrace = time.Minute
+
+// verifyJournal runs the verify-journal subcommand, which checks a decision
+// journal and its rotated files for records that were modified, removed or
+// reordered, and returns the exit code
+func verifyJournal(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("verify-journal", flag.ContinueOnError)
+	flags.SetOutput(out)
+	publicKey := flags.String("public-key", "", "PEM Ed25519 public key to check checkpoint signatures with")
+	strict := flags.Bool("strict", false, "fail on warnings too, e.g. records not covered by a checkpoint")
+	headFile := flags.String("head-file", "", "file holding the last checkpoint written (default <journal path>.head if it exists)")
+	expectSeq := flags.Uint64("expect-seq", 0, "record the journal must reach, e.g. from a logged checkpoint")
+	expectHead := flags.String("expect-head", "", "hash record -expect-seq must have")
+	interval := flags.Duration("checkpoint-interval", 5*time.Minute, "journal.checkpoint_interval, warn if the last checkpoint is older; 0 for archived journals")
+	flags.Usage = func() {
+		fmt.Fprintln(out, "Usage: verify-journal [-public-key key.pub] [-strict] [-head-file file] [-expect-seq n [-expect-head hash]] [-checkpoint-interval 5m] <journal path>")
+		fmt.Fprintln(out, "Verifies the journal file and the rotated files next to it, oldest first.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return verifyInvalid
+	}
+	if flags.NArg() != 1 || (*expectHead != "" && *expectSeq == 0) {
+		flags.Usage()
+		return verifyInvalid
+	}
+
+	opts := journal.VerifyOptions{
+		HeadFile:   *headFile,
+		ExpectSeq:  *expectSeq,
+		ExpectHead: *expectHead,
+	}
+	if *interval > 0 {
+		opts.MaxCheckpointAge = *interval + checkpointGrace
+	}
+	if *publicKey != "" {
+		var err error
+		if opts.Key, err = journal.LoadPublicKey(*publicKey); err != nil {
+			fmt.Fprintln(out, "Error:", err)
+			return verifyInvalid
+		}
+	}
+
+	report, err := journal.VerifyJournal(flags.Arg(0), opts)
+	if err != nil {
+		fmt.Fprintln(out, "Error:", err)
+		return verifyInvalid
+	}
+
+	fmt.Fprintf(out, "Verified %d files: %d decisions and %d checkpoints, records %d to %d\n",
+		len(report.Files), report.Records, report.Checkpoints, report.FirstSeq, report.LastSeq)
+	if opts.Key != nil {
+		fmt.Fprintf(out, "%d checkpoints signed by key %s\n", report.Signed, journal.KeyID(opts.Key))
+	}
+	if !report.LastCheckpointTime.IsZero() {
+		fmt.Fprintf(out, "Last checkpoint covers record %d, written %s\n",
+			report.LastCheckpoint, report.LastCheckpointTime.Format(time.RFC3339))
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintln(out, "WARNING:", warning)
+	}
+	for _, problem := range report.Problems {
+		fmt.Fprintln(out, "FAIL:", problem)
+	}
+
+	switch {
+	case !report.OK():
+		fmt.Fprintf(out, "Journal verification FAILED: %d problems found\n", len(report.Problems))
+		return verifyFailed
+	case *strict && len(report.Warnings) > 0:
+		fmt.Fprintln(out, "Journal verification FAILED: warnings are fatal with -strict")
+		return verifyFailed
+	case !report.Verified():
+		fmt.Fprintln(out, "Journal could not be verified:", report.Unverified)
+		return verifyUnverified
+	}
+	fmt.Fprintln(out, "Journal is intact")
+	return verifyOK
+}